@@ -0,0 +1,284 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+type audience struct {
+	ID        string   `json:"id"`
+	Number    string   `json:"number"`
+	Building  string   `json:"building"`
+	Floor     int      `json:"floor"`
+	Suffix    *string  `json:"suffix,omitempty"`
+	Capacity  *int     `json:"capacity,omitempty"`
+	Kind      *string  `json:"kind,omitempty"`
+	Equipment []string `json:"equipment"`
+}
+
+func audienceToAPI(a service.Audience) audience {
+	res := audience{
+		ID:        a.ID,
+		Number:    a.Number,
+		Building:  a.Building,
+		Floor:     a.Floor,
+		Suffix:    a.Suffix,
+		Capacity:  a.Capacity,
+		Kind:      a.Kind,
+		Equipment: a.Equipment,
+	}
+	if res.Equipment == nil {
+		res.Equipment = []string{}
+	}
+	return res
+}
+
+type freeAudience struct {
+	audience
+	// NextBusyPeriod is omitted if the audience stays free till the end of the day
+	NextBusyPeriod        int        `json:"next_busy_period,omitempty"`
+	ClaimedUntil          *time.Time `json:"claimed_until,omitempty"`
+	UnavailableConfidence float64    `json:"unavailable_confidence"`
+	Suspicious            bool       `json:"suspicious"`
+}
+
+type freeAudiences struct {
+	WeekType  string         `json:"week_type"`
+	Days      []string       `json:"days"`
+	Periods   []int          `json:"periods"`
+	Audiences []freeAudience `json:"audiences"`
+}
+
+func (s *Server) freeAudiences(w http.ResponseWriter, r *http.Request) error {
+	now := s.now()
+	filter, err := freeAudiencesFilter(r.URL.Query(), s.calendar.CurrentSlot(now), now)
+	if err != nil {
+		return err
+	}
+
+	audiences, err := s.srvc.ListEmptyAudiences(r.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	res := freeAudiences{
+		WeekType:  filter.WeekType,
+		Days:      filter.WeekDays,
+		Periods:   filter.Periods,
+		Audiences: make([]freeAudience, 0, len(audiences)),
+	}
+	for _, a := range audiences {
+		res.Audiences = append(res.Audiences, freeAudience{
+			audience:              audienceToAPI(a.Audience),
+			NextBusyPeriod:        a.NextBusyPeriod,
+			ClaimedUntil:          a.ClaimedUntil,
+			UnavailableConfidence: a.UnavailableConfidence,
+			Suspicious:            a.Suspicious(),
+		})
+	}
+	s.writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+type lesson struct {
+	Name    string  `json:"name"`
+	Teacher *string `json:"teacher,omitempty"`
+	Kind    *string `json:"kind,omitempty"`
+}
+
+type scheduleEntry struct {
+	WeekType string   `json:"week_type"`
+	Day      string   `json:"day"`
+	Period   int      `json:"period"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Lesson   lesson   `json:"lesson"`
+	Audience audience `json:"audience"`
+	Groups   []string `json:"groups"`
+}
+
+type schedule struct {
+	Entries []scheduleEntry `json:"entries"`
+}
+
+func scheduleToAPI(entries []service.ScheduleEntry) schedule {
+	res := schedule{Entries: make([]scheduleEntry, 0, len(entries))}
+	for _, e := range entries {
+		entry := scheduleEntry{
+			WeekType: e.WeekType,
+			Day:      e.WeekDay,
+			Period:   e.Period,
+			Lesson: lesson{
+				Name:    e.Lesson.Name,
+				Teacher: e.Lesson.TeacherName,
+				Kind:    e.Lesson.Kind,
+			},
+			Audience: audienceToAPI(e.Audience),
+			Groups:   e.Groups,
+		}
+		if b, ok := service.BellByPeriod(e.Period); ok {
+			entry.Start, entry.End = b.StartString(), b.EndString()
+		}
+		if entry.Groups == nil {
+			entry.Groups = []string{}
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return res
+}
+
+func (s *Server) audienceSchedule(w http.ResponseWriter, r *http.Request, id string) error {
+	if err := audienceID(id); err != nil {
+		return err
+	}
+	weekType, weekDay, err := scheduleFilter(r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	aud, err := s.srvc.GetAudience(r.Context(), id)
+	if err != nil {
+		return fmt.Errorf("cannot get audience: %w", err)
+	}
+	entries, err := s.srvc.ListScheduleEntries(r.Context(), &service.ScheduleEntryFilters{
+		AudienceIDs: []string{aud.ID},
+		WeekType:    weekType,
+		WeekDay:     weekDay,
+	})
+	if err != nil {
+		return err
+	}
+	s.writeJSON(w, http.StatusOK, scheduleToAPI(entries))
+	return nil
+}
+
+type group struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type groups struct {
+	Groups []group `json:"groups"`
+}
+
+// groups lists all groups, or groups matching the name parameter the way the bot searches them.
+func (s *Server) groups(w http.ResponseWriter, r *http.Request) error {
+	var (
+		found []service.Group
+		err   error
+	)
+	if name := r.URL.Query().Get("name"); name != "" {
+		found, err = s.srvc.SearchGroups(r.Context(), name)
+	} else {
+		found, err = s.srvc.ListGroups(r.Context(), &service.GroupFilters{})
+	}
+	if err != nil && !errors.Is(err, service.ErrorNotFound) {
+		return err
+	}
+
+	res := groups{Groups: make([]group, 0, len(found))}
+	for _, g := range found {
+		res.Groups = append(res.Groups, group{ID: g.ID, Name: g.Name})
+	}
+	s.writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+func ambiguous(kind string, names []string) error {
+	return &httpError{
+		status:  http.StatusConflict,
+		code:    "ambiguous",
+		message: "several " + kind + " match: " + strings.Join(names, ", "),
+	}
+}
+
+// resolveGroup finds the only group matching the name.
+func (s *Server) resolveGroup(ctx context.Context, name string) (service.Group, error) {
+	found, err := s.srvc.SearchGroups(ctx, name)
+	if err != nil {
+		return service.Group{}, err
+	}
+	if len(found) > 1 {
+		names := make([]string, 0, len(found))
+		for _, g := range found {
+			names = append(names, g.Name)
+		}
+		return service.Group{}, ambiguous("groups", names)
+	}
+	return found[0], nil
+}
+
+func (s *Server) groupSchedule(w http.ResponseWriter, r *http.Request, name string) error {
+	weekType, weekDay, err := scheduleFilter(r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	g, err := s.resolveGroup(r.Context(), name)
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.srvc.ListGroupSchedule(r.Context(), g.ID, weekType, weekDay)
+	if err != nil {
+		return err
+	}
+	s.writeJSON(w, http.StatusOK, scheduleToAPI(entries))
+	return nil
+}
+
+type teachers struct {
+	Teachers []string `json:"teachers"`
+}
+
+// teachers searches teachers by the words of the name parameter, there are too many to list them all.
+func (s *Server) teachers(w http.ResponseWriter, r *http.Request) error {
+	found, err := s.srvc.SearchTeachers(r.Context(), r.URL.Query().Get("name"))
+	if err != nil && !errors.Is(err, service.ErrorNotFound) {
+		return err
+	}
+	if found == nil {
+		found = []string{}
+	}
+	s.writeJSON(w, http.StatusOK, teachers{Teachers: found})
+	return nil
+}
+
+type floor struct {
+	Floor     int `json:"floor"`
+	Audiences int `json:"audiences"`
+}
+
+type building struct {
+	Name      string  `json:"name"`
+	Audiences int     `json:"audiences"`
+	Floors    []floor `json:"floors"`
+}
+
+type buildings struct {
+	Buildings []building `json:"buildings"`
+}
+
+func (s *Server) buildings(w http.ResponseWriter, r *http.Request) error {
+	found, err := s.srvc.ListBuildings(r.Context())
+	if err != nil {
+		return err
+	}
+
+	res := buildings{Buildings: make([]building, 0, len(found))}
+	for _, b := range found {
+		floors := make([]floor, 0, len(b.Floors))
+		for _, f := range b.Floors {
+			floors = append(floors, floor{Floor: f.Floor, Audiences: f.Audiences})
+		}
+		res.Buildings = append(res.Buildings, building{Name: b.Name, Audiences: b.Audiences, Floors: floors})
+	}
+	s.writeJSON(w, http.StatusOK, res)
+	return nil
+}
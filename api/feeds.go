@@ -0,0 +1,80 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/icsfeed"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// writeFeed renders the whole feed before writing, so a failure still gets a proper error response.
+func (s *Server) writeFeed(w http.ResponseWriter, r *http.Request, feed icsfeed.Feed) error {
+	var buf bytes.Buffer
+	if err := icsfeed.Write(&buf, s.calendar, feed, s.now()); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "max-age=3600")
+	if _, err := buf.WriteTo(w); err != nil {
+		s.logger.WithError(err).Warning("cannot write feed")
+	}
+	return nil
+}
+
+func (s *Server) audienceFeed(w http.ResponseWriter, r *http.Request, id string) error {
+	if err := audienceID(id); err != nil {
+		return err
+	}
+	aud, err := s.srvc.GetAudience(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	entries, err := s.srvc.ListScheduleEntries(r.Context(), &service.ScheduleEntryFilters{AudienceIDs: []string{aud.ID}})
+	if err != nil {
+		return err
+	}
+
+	number := aud.Number
+	if aud.Suffix != nil {
+		number += *aud.Suffix
+	}
+	return s.writeFeed(w, r, icsfeed.Feed{ID: "audience:" + aud.ID, Name: number + " (" + aud.Building + ")", Entries: entries})
+}
+
+func (s *Server) groupFeed(w http.ResponseWriter, r *http.Request, name string) error {
+	g, err := s.resolveGroup(r.Context(), name)
+	if err != nil {
+		return err
+	}
+	entries, err := s.srvc.ListGroupSchedule(r.Context(), g.ID, nil, nil)
+	if err != nil {
+		return err
+	}
+	return s.writeFeed(w, r, icsfeed.Feed{ID: "group:" + g.ID, Name: g.Name, Entries: entries})
+}
+
+// resolveTeacher finds the only teacher whose name contains every word of the query.
+func (s *Server) resolveTeacher(ctx context.Context, name string) (string, error) {
+	found, err := s.srvc.SearchTeachers(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if len(found) > 1 {
+		return "", ambiguous("teachers", found)
+	}
+	return found[0], nil
+}
+
+func (s *Server) teacherFeed(w http.ResponseWriter, r *http.Request, name string) error {
+	teacher, err := s.resolveTeacher(r.Context(), name)
+	if err != nil {
+		return err
+	}
+	entries, err := s.srvc.ListTeacherSchedule(r.Context(), teacher, nil, nil)
+	if err != nil {
+		return err
+	}
+	return s.writeFeed(w, r, icsfeed.Feed{ID: "teacher:" + teacher, Name: teacher, Entries: entries})
+}
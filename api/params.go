@@ -0,0 +1,157 @@
+package api
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// weekTypes accepts latin names besides ЧС and ЗН, they are easier to put in a URL.
+var weekTypes = map[string]string{
+	strings.ToLower(service.WeekTypeNumerator):   service.WeekTypeNumerator,
+	strings.ToLower(service.WeekTypeDenominator): service.WeekTypeDenominator,
+	"numerator":   service.WeekTypeNumerator,
+	"denominator": service.WeekTypeDenominator,
+}
+
+// list returns values of a repeated or comma separated parameter, ?floor=3&floor=4 or ?floor=3,4.
+func list(q url.Values, key string) []string {
+	var res []string
+	for _, v := range q[key] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				res = append(res, item)
+			}
+		}
+	}
+	return res
+}
+
+func ints(q url.Values, key string) ([]int, error) {
+	values := list(q, key)
+	res := make([]int, 0, len(values))
+	for _, v := range values {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, badRequest("%s must be a number, got %q", key, v)
+		}
+		res = append(res, n)
+	}
+	return res, nil
+}
+
+func weekType(q url.Values) (*string, error) {
+	v := strings.TrimSpace(q.Get("week_type"))
+	if v == "" {
+		return nil, nil
+	}
+	wt, ok := weekTypes[strings.ToLower(v)]
+	if !ok {
+		return nil, badRequest("unknown week_type %q", v)
+	}
+	return &wt, nil
+}
+
+// weekDay accepts english names in any case, e.g. monday.
+func weekDay(v string) (string, error) {
+	for _, d := range service.WeekDays() {
+		if strings.EqualFold(d, v) {
+			return d, nil
+		}
+	}
+	return "", badRequest("unknown day %q", v)
+}
+
+func lower(values []string) []string {
+	for i, v := range values {
+		values[i] = strings.ToLower(v)
+	}
+	return values
+}
+
+// freeAudiencesFilter reads the filter from the query. The week type, days and periods default to
+// the current or the next period. Claims count at claims_at, or now if the filter asks about
+// the current or the next period: a claim made now says nothing about another day.
+func freeAudiencesFilter(q url.Values, slot service.Slot, now time.Time) (*service.EmptyAudiencesFilter, error) {
+	filter := &service.EmptyAudiencesFilter{
+		Buildings: list(q, "building"),
+		WeekType:  slot.WeekType,
+		WeekDays:  []string{slot.WeekDay},
+		Periods:   []int{slot.Period},
+		Kinds:     lower(list(q, "kind")),
+		Equipment: lower(list(q, "equipment")),
+	}
+
+	var err error
+	if filter.Floors, err = ints(q, "floor"); err != nil {
+		return nil, err
+	}
+	if wt, err := weekType(q); err != nil {
+		return nil, err
+	} else if wt != nil {
+		filter.WeekType = *wt
+	}
+	if days := list(q, "day"); len(days) > 0 {
+		filter.WeekDays = make([]string, 0, len(days))
+		for _, d := range days {
+			day, err := weekDay(d)
+			if err != nil {
+				return nil, err
+			}
+			filter.WeekDays = append(filter.WeekDays, day)
+		}
+	}
+	if periods, err := ints(q, "period"); err != nil {
+		return nil, err
+	} else if len(periods) > 0 {
+		filter.Periods = periods
+	}
+	if c := q.Get("min_capacity"); c != "" {
+		if filter.MinCapacity, err = strconv.Atoi(c); err != nil || filter.MinCapacity < 0 {
+			return nil, badRequest("min_capacity must be a positive number, got %q", c)
+		}
+	}
+	if h := q.Get("hide_claimed"); h != "" {
+		if filter.HideClaimed, err = strconv.ParseBool(h); err != nil {
+			return nil, badRequest("hide_claimed must be true or false, got %q", h)
+		}
+	}
+	if c := q.Get("claims_at"); c != "" {
+		at, err := time.Parse(time.RFC3339, c)
+		if err != nil {
+			return nil, badRequest("claims_at must be a date-time, e.g. 2026-09-07T10:15:00+03:00, got %q", c)
+		}
+		filter.ClaimsAt = &at
+	} else if filter.Covers(slot) {
+		filter.ClaimsAt = &now
+	}
+
+	return filter, nil
+}
+
+// scheduleFilter reads the optional week_type and day parameters of schedule requests.
+func scheduleFilter(q url.Values) (weekTypeFilter, weekDayFilter *string, err error) {
+	if weekTypeFilter, err = weekType(q); err != nil {
+		return nil, nil, err
+	}
+	if d := strings.TrimSpace(q.Get("day")); d != "" {
+		day, err := weekDay(d)
+		if err != nil {
+			return nil, nil, err
+		}
+		weekDayFilter = &day
+	}
+	return weekTypeFilter, weekDayFilter, nil
+}
+
+func audienceID(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return badRequest("invalid audience id %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+// Package api serves free audiences, timetables, groups, teachers and buildings as JSON over HTTP,
+// for web pages and other projects which want the data rather than a chat. Timetables of rooms,
+// groups and teachers are also served as iCalendar feeds under /feeds.
+//
+// All endpoints answer GET requests, openapi.yaml describes them and is served at /openapi.yaml.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+//go:embed openapi.yaml
+var openAPI []byte
+
+type Config struct {
+	// Listen is the address of the HTTP server, e.g. ":8080"
+	Listen string `yaml:"listen"`
+	// AllowOrigin is sent in Access-Control-Allow-Origin, e.g. "*" to let any web page use the API
+	AllowOrigin string `yaml:"allow_origin"`
+}
+
+type Server struct {
+	srvc     *service.Service
+	calendar *service.Calendar
+	logger   *logrus.Logger
+	conf     Config
+	// now is time.Now, the current period is the default of free audience requests
+	now func() time.Time
+}
+
+func NewServer(srvc *service.Service, calendar *service.Calendar, logger *logrus.Logger, conf Config) *Server {
+	return &Server{
+		srvc:     srvc,
+		calendar: calendar,
+		logger:   logger,
+		conf:     conf,
+		now:      time.Now,
+	}
+}
+
+// Handler routes requests to the endpoints.
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.conf.AllowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", s.conf.AllowOrigin)
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			s.writeError(w, r, &httpError{status: http.StatusMethodNotAllowed, code: "method_not_allowed", message: "only GET is supported"})
+			return
+		}
+
+		var err error
+		path := strings.TrimSuffix(r.URL.Path, "/")
+		switch {
+		case path == "/openapi.yaml":
+			w.Header().Set("Content-Type", "application/yaml")
+			_, err = w.Write(openAPI)
+		case path == "/audiences/free":
+			err = s.freeAudiences(w, r)
+		case path == "/groups":
+			err = s.groups(w, r)
+		case path == "/teachers":
+			err = s.teachers(w, r)
+		case path == "/buildings":
+			err = s.buildings(w, r)
+		default:
+			if id, ok := pathParam(path, "/audiences/", "/schedule"); ok {
+				err = s.audienceSchedule(w, r, id)
+			} else if name, ok := pathParam(path, "/groups/", "/schedule"); ok {
+				err = s.groupSchedule(w, r, name)
+			} else if id, ok := pathParam(path, "/feeds/audiences/", ".ics"); ok {
+				err = s.audienceFeed(w, r, id)
+			} else if name, ok := pathParam(path, "/feeds/groups/", ".ics"); ok {
+				err = s.groupFeed(w, r, name)
+			} else if name, ok := pathParam(path, "/feeds/teachers/", ".ics"); ok {
+				err = s.teacherFeed(w, r, name)
+			} else {
+				err = &httpError{status: http.StatusNotFound, code: "not_found", message: "unknown endpoint " + r.URL.Path}
+			}
+		}
+		if err != nil {
+			s.writeError(w, r, err)
+		}
+	})
+}
+
+// pathParam extracts the segment between prefix and suffix, e.g. the ID of /audiences/{id}/schedule.
+func pathParam(path, prefix, suffix string) (string, bool) {
+	if !strings.HasPrefix(path, prefix) || !strings.HasSuffix(path, suffix) {
+		return "", false
+	}
+	param := strings.TrimSuffix(strings.TrimPrefix(path, prefix), suffix)
+	return param, param != "" && !strings.Contains(param, "/")
+}
+
+// Run serves the API until ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	server := &http.Server{
+		Addr:              s.conf.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			s.logger.WithError(err).Error("cannot shutdown api server")
+		}
+	}()
+
+	s.logger.WithField("addr", s.conf.Listen).Info("serving api")
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("api server failed: %w", err)
+	}
+	return nil
+}
+
+// httpError is an error with the response it deserves, service errors are mapped to it by toHTTPError.
+type httpError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *httpError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &httpError{status: http.StatusBadRequest, code: "invalid_request", message: fmt.Sprintf(format, args...)}
+}
+
+func toHTTPError(err error) *httpError {
+	var (
+		httpErr       *httpError
+		validationErr *service.ValidationError
+	)
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr
+	case errors.As(err, &validationErr):
+		return &httpError{status: http.StatusBadRequest, code: "invalid_request", message: validationErr.Message}
+	case errors.Is(err, service.ErrorNotFound):
+		return &httpError{status: http.StatusNotFound, code: "not_found", message: "not found"}
+	case errors.Is(err, service.ErrorTransient):
+		return &httpError{status: http.StatusServiceUnavailable, code: "unavailable", message: "try again later"}
+	default:
+		return &httpError{status: http.StatusInternalServerError, code: "internal", message: "internal error"}
+	}
+}
+
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	httpErr := toHTTPError(err)
+	if httpErr.status >= http.StatusInternalServerError {
+		s.logger.WithError(err).WithField("path", r.URL.Path).Error("api request failed")
+	}
+	s.writeJSON(w, httpErr.status, errorResponse{Error: httpErr.code, Message: httpErr.message})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.WithError(err).Warning("cannot write api response")
+	}
+}
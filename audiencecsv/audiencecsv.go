@@ -0,0 +1,137 @@
+// Package audiencecsv imports capacity, kind and equipment of audiences from the CSV our team maintains.
+//
+// The file has a header and the columns room, capacity, type, equipment:
+//
+//	room,capacity,type,equipment
+//	395ю,120,lecture,projector;whiteboard
+//	501л,20,computer,computers;sockets
+//
+// Empty cells leave the attribute unknown, equipment items are separated by semicolons.
+package audiencecsv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/icsparser"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var columns = []string{"room", "capacity", "type", "equipment"}
+
+// Result counts rows of the file by outcome.
+type Result struct {
+	Rows    int
+	Updated int
+	// Skipped rows are invalid or name rooms missing in the timetable
+	Skipped int
+}
+
+func parseRow(row []string) (number string, suffix *string, attrs service.AudienceAttributes, err error) {
+	room := strings.TrimSpace(row[0])
+	if room == "" {
+		return "", nil, attrs, errors.New("empty room")
+	}
+	number, suffix = icsparser.SplitLocation(room)
+
+	if c := strings.TrimSpace(row[1]); c != "" {
+		capacity, err := strconv.Atoi(c)
+		if err != nil {
+			return "", nil, attrs, fmt.Errorf("invalid capacity: %w", err)
+		}
+		attrs.Capacity = &capacity
+	}
+	if k := strings.ToLower(strings.TrimSpace(row[2])); k != "" {
+		attrs.Kind = &k
+	}
+	for _, e := range strings.Split(row[3], ";") {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			attrs.Equipment = append(attrs.Equipment, e)
+		}
+	}
+	return number, suffix, attrs, nil
+}
+
+// Import stores attributes of every audience in the file. Invalid rows and unknown rooms are logged
+// and skipped, storage failures stop the import.
+func Import(ctx context.Context, srvc *service.Service, r io.Reader) (Result, error) {
+	log := ctx.Value("logger").(*logrus.Logger)
+	res := Result{}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(columns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return res, fmt.Errorf("cannot read header: %w", err)
+	}
+	for i, name := range columns {
+		if !strings.EqualFold(strings.TrimSpace(header[i]), name) {
+			return res, fmt.Errorf("column %d is %q, want %q", i+1, header[i], name)
+		}
+	}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		res.Rows++
+		if err != nil {
+			res.Skipped++
+			log.WithError(err).WithField("line", line).Warning("skip audience row")
+			continue
+		}
+
+		number, suffix, attrs, err := parseRow(row)
+		if err != nil {
+			res.Skipped++
+			log.WithError(err).WithField("line", line).Warning("skip audience row")
+			continue
+		}
+
+		aud, err := srvc.ListAudienceByNumber(ctx, number, suffix)
+		if errors.Is(err, service.ErrorNotFound) {
+			res.Skipped++
+			log.WithField("line", line).WithField("room", row[0]).Warning("skip unknown audience")
+			continue
+		}
+		if err != nil {
+			return res, fmt.Errorf("cannot get audience on line %d: %w", line, err)
+		}
+
+		attrs.AudienceID = aud.ID
+		err = srvc.SaveAudienceAttributes(ctx, &attrs)
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			res.Skipped++
+			log.WithError(err).WithField("line", line).Warning("skip audience row")
+			continue
+		}
+		if err != nil {
+			return res, fmt.Errorf("cannot save attributes on line %d: %w", line, err)
+		}
+		res.Updated++
+	}
+
+	return res, nil
+}
+
+func ImportFile(ctx context.Context, srvc *service.Service, path string) (Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return Import(ctx, srvc, file)
+}
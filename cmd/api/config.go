@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/api"
+	"github.com/AlexisOMG/bmstu-free-rooms/database"
+)
+
+// Config is the part of the bot config the API needs, the same file can be used.
+type Config struct {
+	Database *database.Config `yaml:"database"`
+	// SemesterStart is the first day of the semester in 2006-01-02 format, used for ЧС/ЗН parity,
+	// guessed from the date if not set
+	SemesterStart *string `yaml:"semester_start"`
+	// API is required, it tells at least where to listen
+	API *api.Config `yaml:"api"`
+}
+
+func readConfig(filename string) (*Config, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	config := &Config{}
+	err = yaml.NewDecoder(file).Decode(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode: %w", err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,74 @@
+// Command api serves free audiences and timetables as JSON, see the api package for endpoints.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/api"
+	"github.com/AlexisOMG/bmstu-free-rooms/database"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+func main() {
+	ctx := context.Background()
+	ctx, cancel := signal.NotifyContext(ctx,
+		syscall.SIGHUP,
+		syscall.SIGINT,
+		syscall.SIGTERM,
+		syscall.SIGQUIT)
+	defer cancel()
+
+	logger := &logrus.Logger{
+		Out:       os.Stdout,
+		Formatter: new(logrus.JSONFormatter),
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.InfoLevel,
+	}
+
+	ctx = context.WithValue(ctx, "logger", logger)
+
+	configPath := flag.String("c", "config.yaml", "path to your config")
+	flag.Parse()
+
+	conf, err := readConfig(*configPath)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to read config")
+	}
+	if conf.API == nil {
+		logger.Fatal("api is not set")
+	}
+	semesterStart, guessed, err := service.ParseSemesterStart(conf.SemesterStart, time.Now())
+	if err != nil {
+		logger.WithError(err).Fatal("invalid semester_start")
+	}
+	if guessed {
+		logger.WithField("semester_start", semesterStart.Format("2006-01-02")).
+			Warning("semester_start is not set, guessed it from the date")
+	}
+
+	storage, err := database.NewDatabase(ctx, conf.Database)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create database")
+	}
+	defer storage.Close(ctx)
+
+	err = storage.Ping(ctx)
+	if err != nil {
+		logger.WithError(err).Fatal("database ping failed")
+	}
+	logger.Info("connected to database")
+
+	srvc := service.NewService(storage)
+	server := api.NewServer(srvc, service.NewCalendar(semesterStart), logger, *conf.API)
+
+	if err := server.Run(ctx); err != nil {
+		logger.WithError(err).Fatal("api stopped")
+	}
+}
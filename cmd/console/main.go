@@ -0,0 +1,179 @@
+// Command console runs the free room dialogue in the terminal, no bot token is needed.
+//
+// Type /start or /now [building] [floor] and answer questions with numbers of the buttons.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/database"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const consoleChatID = "console"
+
+func main() {
+	// Ctrl+C stops the console right away, Ctrl+D or /exit closes it gracefully
+	ctx := context.Background()
+
+	// the dialogue owns stdout, logs go to stderr
+	logger := &logrus.Logger{
+		Out:       os.Stderr,
+		Formatter: new(logrus.TextFormatter),
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.WarnLevel,
+	}
+	ctx = context.WithValue(ctx, "logger", logger)
+
+	configPath := flag.String("c", "config.yaml", "path to your config")
+	lang := flag.String("lang", service.LanguageRussian, "language of the dialogue: ru or en")
+	flag.Parse()
+
+	conf, err := readConfig(*configPath)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to read config")
+	}
+	semesterStart, guessed, err := service.ParseSemesterStart(conf.SemesterStart, time.Now())
+	if err != nil {
+		logger.WithError(err).Fatal("invalid semester_start")
+	}
+	if guessed {
+		logger.WithField("semester_start", semesterStart.Format("2006-01-02")).
+			Warning("semester_start is not set, guessed it from the date")
+	}
+
+	storage, err := database.NewDatabase(ctx, conf.Database)
+	if err != nil {
+		logger.WithError(err).Fatal("failed to create database")
+	}
+	defer storage.Close(ctx)
+
+	if err := storage.Ping(ctx); err != nil {
+		logger.WithError(err).Fatal("database ping failed")
+	}
+
+	core := conversation.NewCore(service.NewService(storage), service.NewCalendar(semesterStart), logger, conf.Telegram)
+	repl := &console{
+		core:   core,
+		loc:    i18n.New(*lang),
+		logger: logger,
+		in:     bufio.NewScanner(os.Stdin),
+		out:    os.Stdout,
+	}
+	if err := repl.run(ctx); err != nil {
+		logger.WithError(err).Fatal("console stopped")
+	}
+}
+
+type console struct {
+	core   *conversation.Core
+	loc    i18n.Localizer
+	logger *logrus.Logger
+	in     *bufio.Scanner
+	out    io.Writer
+	// buttons of the last answer, numbered from 1 in the order they are printed across all its replies
+	buttons []conversation.Button
+}
+
+func (c *console) run(ctx context.Context) error {
+	fmt.Fprintln(c.out, c.loc.T("console.help"))
+	for {
+		fmt.Fprint(c.out, "> ")
+		if !c.in.Scan() {
+			return c.in.Err()
+		}
+		line := strings.TrimSpace(c.in.Text())
+		if line == "" {
+			continue
+		}
+		if line == "/exit" {
+			return nil
+		}
+
+		msg, ok := c.parse(line)
+		if !ok {
+			fmt.Fprintln(c.out, c.loc.T("console.unknown"))
+			continue
+		}
+		msg.ChatID = consoleChatID
+		msg.Lang = c.loc.Lang()
+		if !c.core.Handles(msg) {
+			fmt.Fprintln(c.out, c.loc.T("console.unknown"))
+			continue
+		}
+
+		replies, err := c.core.Handle(ctx, msg)
+		if err != nil {
+			c.printError(err)
+			continue
+		}
+		// a page of results and the question after it share the numbers, so none of their buttons is lost
+		if hasButtons(replies) {
+			c.buttons = c.buttons[:0]
+		}
+		for _, r := range replies {
+			c.print(r)
+		}
+	}
+}
+
+// parse reads a command or a number of the button.
+func (c *console) parse(line string) (conversation.Message, bool) {
+	if strings.HasPrefix(line, "/") {
+		command, args, _ := strings.Cut(strings.TrimPrefix(line, "/"), " ")
+		return conversation.Message{Command: command, Args: strings.TrimSpace(args)}, true
+	}
+	n, err := strconv.Atoi(line)
+	if err != nil || n < 1 || n > len(c.buttons) {
+		return conversation.Message{}, false
+	}
+	return conversation.Message{Data: c.buttons[n-1].Data}, true
+}
+
+func (c *console) print(r conversation.Reply) {
+	fmt.Fprintln(c.out, r.Text)
+	if len(r.Buttons) == 0 {
+		return
+	}
+
+	for _, row := range r.Buttons {
+		texts := make([]string, 0, len(row))
+		for _, b := range row {
+			c.buttons = append(c.buttons, b)
+			texts = append(texts, fmt.Sprintf("[%d] %s", len(c.buttons), b.Text))
+		}
+		fmt.Fprintln(c.out, "  "+strings.Join(texts, "  "))
+	}
+}
+
+func hasButtons(replies []conversation.Reply) bool {
+	for _, r := range replies {
+		if len(r.Buttons) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *console) printError(err error) {
+	var userErr *conversation.UserError
+	if errors.As(err, &userErr) {
+		fmt.Fprintln(c.out, userErr.Text)
+		return
+	}
+	c.logger.WithError(err).Error("cannot handle message")
+	fmt.Fprintln(c.out, c.loc.T("error.generic"))
+}
@@ -7,12 +7,21 @@ import (
 	"gopkg.in/yaml.v2"
 
 	"github.com/AlexisOMG/bmstu-free-rooms/database"
+	"github.com/AlexisOMG/bmstu-free-rooms/grpcapi"
+	"github.com/AlexisOMG/bmstu-free-rooms/handlers"
 )
 
 type Config struct {
 	Database    *database.Config `yaml:"database"`
 	ScheduleDir *string          `yaml:"schedule_dir"`
 	Token       *string          `yaml:"bot_token"`
+	// Telegram is optional, without it the bot uses long polling against the public Bot API
+	Telegram *handlers.TelegramConfig `yaml:"telegram"`
+	// SemesterStart is the first day of the semester in 2006-01-02 format, used for ЧС/ЗН parity,
+	// guessed from the date if not set
+	SemesterStart *string `yaml:"semester_start"`
+	// GRPC serves schedule queries to other services alongside the bot, it is off without the section
+	GRPC *grpcapi.Config `yaml:"grpc"`
 }
 
 func readConfig(filename string) (*Config, error) {
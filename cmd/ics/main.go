@@ -6,12 +6,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/AlexisOMG/bmstu-free-rooms/audiencecsv"
 	"github.com/AlexisOMG/bmstu-free-rooms/database"
+	"github.com/AlexisOMG/bmstu-free-rooms/grpcapi"
 	"github.com/AlexisOMG/bmstu-free-rooms/handlers"
-	"github.com/AlexisOMG/bmstu-free-rooms/icsparser"
 	"github.com/AlexisOMG/bmstu-free-rooms/service"
 )
 
@@ -35,6 +37,7 @@ func main() {
 
 	configPath := flag.String("c", "config.yaml", "path to your config")
 	needDownload := flag.Bool("p", false, "use it for downloading schedule")
+	audiencesCSV := flag.String("a", "", "path to the CSV with capacity, type and equipment of audiences to import")
 	flag.Parse()
 
 	conf, err := readConfig(*configPath)
@@ -56,21 +59,63 @@ func main() {
 
 	srvc := service.NewService(storage)
 
+	var importer *handlers.ScheduleImporter
+	if conf.ScheduleDir != nil {
+		importer = handlers.NewScheduleImporter(srvc, *conf.ScheduleDir)
+	}
+
 	if needDownload != nil && *needDownload {
-		downloader := handlers.NewICSDownloader(*conf.ScheduleDir)
-		err = downloader.DownloadICS(ctx)
+		if importer == nil {
+			logger.Fatal("schedule_dir is not set")
+		}
+		imp, err := importer.Import(ctx, true)
 		if err != nil {
-			logger.WithError(err).Fatal("ics loading failed")
+			logger.WithError(err).Fatal("schedule import failed")
 		}
-		logger.Info("loaded ics files")
-		err = icsparser.ProcessICSFiles(ctx, srvc, *conf.ScheduleDir)
+		logger.WithFields(logrus.Fields{
+			"files":              imp.Files,
+			"imported":           imp.Imported,
+			"quarantined_files":  imp.QuarantinedFiles,
+			"quarantined_events": imp.QuarantinedEvents,
+		}).Info("processed ics files")
+	}
+
+	if *audiencesCSV != "" {
+		res, err := audiencecsv.ImportFile(ctx, srvc, *audiencesCSV)
 		if err != nil {
-			logger.WithError(err).Fatal("ics processing failed")
+			logger.WithError(err).Fatal("audience import failed")
 		}
-		logger.Info("processed ics files")
+		logger.WithFields(logrus.Fields{
+			"rows":    res.Rows,
+			"updated": res.Updated,
+			"skipped": res.Skipped,
+		}).Info("imported audience attributes")
 	}
 
-	bot := handlers.NewBot(*conf.Token)
+	semesterStart, guessed, err := service.ParseSemesterStart(conf.SemesterStart, time.Now())
+	if err != nil {
+		logger.WithError(err).Fatal("invalid semester_start")
+	}
+	if guessed {
+		logger.WithField("semester_start", semesterStart.Format("2006-01-02")).
+			Warning("semester_start is not set, guessed it from the date")
+	}
+
+	calendar := service.NewCalendar(semesterStart)
+
+	if conf.GRPC != nil {
+		grpcServer := grpcapi.NewServer(srvc, calendar, logger, *conf.GRPC)
+		go func() {
+			if err := grpcServer.Run(ctx); err != nil {
+				logger.WithError(err).Error("grpc server stopped")
+				cancel()
+			}
+		}()
+	}
+
+	bot := handlers.NewBot(*conf.Token, calendar, conf.Telegram, importer)
 
-	bot.Listen(ctx, srvc)
+	if err := bot.Listen(ctx, srvc); err != nil {
+		logger.WithError(err).Fatal("bot stopped")
+	}
 }
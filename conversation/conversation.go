@@ -0,0 +1,205 @@
+// Package conversation holds the free room dialogue apart from any messenger.
+// An adapter turns what the user sends into a Message and shows the returned Replies.
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// Button is an answer the user can pick instead of typing it.
+type Button struct {
+	Text string
+	// Data comes back in Message.Data when the button is pressed, it does not depend on the language
+	Data string
+}
+
+// Reply is one message of the bot.
+type Reply struct {
+	Text    string
+	Buttons [][]Button
+	// Edit replaces the message whose button was pressed, adapters which cannot edit send it anew
+	Edit bool
+}
+
+// Message is a command or a pressed button.
+type Message struct {
+	// ChatID identifies the dialogue, answers to buttons are matched with earlier questions by it
+	ChatID string
+	// UserID is the ID of the registered user, empty if the messenger has no users
+	UserID string
+	// Lang is one of service.Language*, Russian if empty
+	Lang    string
+	Command string
+	Args    string
+	// Data of the pressed button, Command is empty then
+	Data string
+}
+
+// UserError carries a text which is safe to show to the user instead of the generic one.
+type UserError struct {
+	Text string
+	Err  error
+}
+
+func (e *UserError) Error() string {
+	if e.Err == nil {
+		return e.Text
+	}
+	return e.Text + ": " + e.Err.Error()
+}
+
+func (e *UserError) Unwrap() error {
+	return e.Err
+}
+
+func NewUserError(text string, err error) error {
+	return &UserError{Text: text, Err: err}
+}
+
+type Core struct {
+	srvc     *service.Service
+	calendar *service.Calendar
+	logger   *logrus.Logger
+	sessions *sessions
+	pages    *pageCache
+	// buildings are the names ParseBuilding knows, taken from the database
+	buildings *buildingNames
+	// pageSize is how many rooms a page of free room results shows
+	pageSize        int
+	floorFreeCounts bool
+}
+
+// Options tune the dialogue, the zero value is the default.
+type Options struct {
+	// PageSize is how many rooms a page of free room results shows, DefaultPageSize if not positive
+	PageSize int `yaml:"page_size"`
+	// FloorFreeCounts shows on floor buttons how many audiences are free all day, it costs a query per floor list
+	FloorFreeCounts bool `yaml:"floor_free_counts"`
+}
+
+func NewCore(srvc *service.Service, calendar *service.Calendar, logger *logrus.Logger, opts Options) *Core {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	return &Core{
+		srvc:            srvc,
+		calendar:        calendar,
+		logger:          logger,
+		sessions:        newSessions(),
+		pages:           newPageCache(),
+		buildings:       &buildingNames{},
+		pageSize:        opts.PageSize,
+		floorFreeCounts: opts.FloorFreeCounts,
+	}
+}
+
+// buildingsTTL is how long the names of buildings are cached, an import may bring new ones
+const buildingsTTL = 10 * time.Minute
+
+type buildingNames struct {
+	mu      sync.Mutex
+	names   []string
+	fetched time.Time
+}
+
+// KnownBuildings returns names of the buildings with audiences, queries can mention them.
+func (c *Core) KnownBuildings(ctx context.Context) ([]string, error) {
+	c.buildings.mu.Lock()
+	defer c.buildings.mu.Unlock()
+	if c.buildings.names != nil && time.Since(c.buildings.fetched) < buildingsTTL {
+		return c.buildings.names, nil
+	}
+
+	found, err := c.srvc.ListBuildings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list buildings: %w", err)
+	}
+	names := make([]string, 0, len(found))
+	for _, b := range found {
+		names = append(names, b.Name)
+	}
+	c.buildings.names, c.buildings.fetched = names, time.Now()
+	return names, nil
+}
+
+// Handles tells whether the message is a part of the dialogue, others are up to the adapter.
+func (c *Core) Handles(msg Message) bool {
+	if msg.Data != "" {
+		return strings.HasPrefix(msg.Data, queryCallbackPrefix) || strings.HasPrefix(msg.Data, pageCallbackPrefix) ||
+			strings.HasPrefix(msg.Data, reportCallbackPrefix)
+	}
+	return msg.Command == "start" || msg.Command == "now"
+}
+
+// Handle answers the message, errors which are not UserError should be shown as a generic failure.
+func (c *Core) Handle(ctx context.Context, msg Message) ([]Reply, error) {
+	loc := i18n.New(msg.Lang)
+	if strings.HasPrefix(msg.Data, pageCallbackPrefix) {
+		return c.handlePage(loc, msg.Data)
+	}
+	if strings.HasPrefix(msg.Data, reportCallbackPrefix) {
+		return c.handleReport(ctx, loc, msg)
+	}
+	if msg.Data != "" {
+		return c.handleQueryAnswer(ctx, loc, msg)
+	}
+
+	switch msg.Command {
+	case "start":
+		return c.startQuery(loc, msg.ChatID), nil
+	case "now":
+		return c.handleNow(ctx, loc, msg)
+	default:
+		return nil, nil
+	}
+}
+
+// preferences returns preferences of the user, the defaults if there is no user or they cannot be loaded.
+func (c *Core) preferences(ctx context.Context, userID string) service.Preferences {
+	if userID == "" {
+		return service.Preferences{Notifications: true}
+	}
+	prefs, err := c.srvc.GetPreferences(ctx, userID)
+	if err != nil {
+		c.logger.WithError(err).Error("cannot get preferences")
+		return service.Preferences{UserID: userID, Notifications: true}
+	}
+	return prefs
+}
+
+// sessions keeps answers of the unfinished free room query of every chat.
+type sessions struct {
+	mu      sync.Mutex
+	filters map[string]service.EmptyAudiencesFilter
+}
+
+func newSessions() *sessions {
+	return &sessions{filters: make(map[string]service.EmptyAudiencesFilter)}
+}
+
+func (s *sessions) get(chatID string) service.EmptyAudiencesFilter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.filters[chatID]
+}
+
+func (s *sessions) set(chatID string, filter service.EmptyAudiencesFilter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters[chatID] = filter
+}
+
+func (s *sessions) delete(chatID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.filters, chatID)
+}
@@ -0,0 +1,223 @@
+package conversation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	// buildingAliases lets the buildings be typed in latin letters
+	buildingAliases = map[string]string{
+		"gz":   "ГЗ",
+		"main": "ГЗ",
+		"ulk":  "УЛК",
+	}
+
+	audienceKindWords = map[string]string{
+		"лекционная":   service.AudienceKindLecture,
+		"лекционную":   service.AudienceKindLecture,
+		"поточная":     service.AudienceKindLecture,
+		"поточную":     service.AudienceKindLecture,
+		"компьютерный": service.AudienceKindComputer,
+		"компьютерная": service.AudienceKindComputer,
+		"компьютерную": service.AudienceKindComputer,
+		"дисплейный":   service.AudienceKindComputer,
+		"лаборатория":  service.AudienceKindLab,
+		"лабораторию":  service.AudienceKindLab,
+		"лаба":         service.AudienceKindLab,
+		"лабу":         service.AudienceKindLab,
+		"семинарская":  service.AudienceKindSeminar,
+		"семинарскую":  service.AudienceKindSeminar,
+		"семинар":      service.AudienceKindSeminar,
+		"lecture":      service.AudienceKindLecture,
+		"computer":     service.AudienceKindComputer,
+		"lab":          service.AudienceKindLab,
+		"seminar":      service.AudienceKindSeminar,
+	}
+
+	equipmentWords = map[string]string{
+		"проектор":     service.EquipmentProjector,
+		"проектором":   service.EquipmentProjector,
+		"доска":        service.EquipmentWhiteboard,
+		"доской":       service.EquipmentWhiteboard,
+		"компьютеры":   service.EquipmentComputers,
+		"компьютерами": service.EquipmentComputers,
+		"пк":           service.EquipmentComputers,
+		"розетки":      service.EquipmentSockets,
+		"розетками":    service.EquipmentSockets,
+		"projector":    service.EquipmentProjector,
+		"whiteboard":   service.EquipmentWhiteboard,
+		"computers":    service.EquipmentComputers,
+		"pcs":          service.EquipmentComputers,
+		"sockets":      service.EquipmentSockets,
+	}
+
+	capacityWords = map[string]bool{
+		"человек": true, "чел": true, "мест": true, "места": true, "people": true, "persons": true, "seats": true,
+	}
+
+	// fillerWords may stand between the parts of a query, as in "на 20 человек с проектором"
+	fillerWords = map[string]bool{"на": true, "с": true, "со": true, "для": true, "for": true, "with": true}
+)
+
+// ParseAudienceKind recognizes a kind of audience, e.g. "компьютерный" or "lab".
+func ParseAudienceKind(word string) (string, bool) {
+	k, ok := audienceKindWords[strings.ToLower(word)]
+	return k, ok
+}
+
+// ParseEquipment recognizes equipment, e.g. "проектор" or "sockets".
+func ParseEquipment(word string) (string, bool) {
+	e, ok := equipmentWords[strings.ToLower(word)]
+	return e, ok
+}
+
+// IsCapacityWord tells whether the number before the word is the number of people, as in "20 человек".
+func IsCapacityWord(word string) bool {
+	return capacityWords[strings.ToLower(word)]
+}
+
+// IsFillerWord tells whether the word carries no meaning in a query.
+func IsFillerWord(word string) bool {
+	return fillerWords[strings.ToLower(word)]
+}
+
+// ParseBuilding recognizes one of the buildings, see Core.KnownBuildings, or its latin alias.
+func ParseBuilding(arg string, buildings []string) (string, bool) {
+	for _, b := range buildings {
+		if strings.EqualFold(arg, b) {
+			return b, true
+		}
+	}
+	b, ok := buildingAliases[strings.ToLower(arg)]
+	return b, ok
+}
+
+// nowArgs are the optional arguments of /now.
+type nowArgs struct {
+	building    string
+	floor       int
+	minCapacity int
+	kinds       []string
+	equipment   []string
+}
+
+// parseNowArgs accepts "[building] [floor] [kind] [equipment] [N people]" in any order.
+func parseNowArgs(args string, buildings []string) (nowArgs, error) {
+	res := nowArgs{}
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		arg := fields[i]
+		if b, ok := ParseBuilding(arg, buildings); ok {
+			res.building = b
+			continue
+		}
+		if k, ok := ParseAudienceKind(arg); ok {
+			res.kinds = append(res.kinds, k)
+			continue
+		}
+		if e, ok := ParseEquipment(arg); ok {
+			res.equipment = append(res.equipment, e)
+			continue
+		}
+		if IsFillerWord(arg) || IsCapacityWord(arg) {
+			continue
+		}
+		n, convErr := strconv.Atoi(arg)
+		if convErr != nil || n <= 0 {
+			return nowArgs{}, fmt.Errorf("unknown argument: %s", arg)
+		}
+		if i+1 < len(fields) && IsCapacityWord(fields[i+1]) {
+			res.minCapacity = n
+			i++
+			continue
+		}
+		res.floor = n
+	}
+	return res, nil
+}
+
+func SameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func SlotDescription(loc i18n.Localizer, slot service.Slot, now time.Time) string {
+	if slot.InProgress {
+		return loc.T("slot.in_progress",
+			slot.Period, slot.Start.Format("15:04"), slot.End.Format("15:04"), loc.WeekType(slot.WeekType))
+	}
+
+	day := loc.OnWeekDay(slot.WeekDay)
+	switch now = now.In(service.MoscowLocation); {
+	case SameDay(slot.Start, now):
+		day = loc.T("day.today")
+	case SameDay(slot.Start, now.AddDate(0, 0, 1)):
+		day = loc.T("day.tomorrow")
+	}
+	return loc.T("slot.next", slot.Period, day, slot.Start.Format("15:04"), loc.WeekType(slot.WeekType))
+}
+
+func PeriodsDescription(loc i18n.Localizer, periods []int) string {
+	switch {
+	case len(periods) == 1:
+		return loc.T("periods.one", periods[0])
+	case periods[len(periods)-1]-periods[0] == len(periods)-1:
+		return loc.T("periods.range", periods[0], periods[len(periods)-1])
+	default:
+		parts := make([]string, 0, len(periods))
+		for _, p := range periods {
+			parts = append(parts, strconv.Itoa(p))
+		}
+		return loc.T("periods.list", strings.Join(parts, ", "))
+	}
+}
+
+// FreeUntil tells how long the audience stays free after the requested periods.
+func FreeUntil(loc i18n.Localizer, aud service.EmptyAudience) string {
+	b, ok := service.BellByPeriod(aud.NextBusyPeriod)
+	if !ok {
+		return loc.T("free.until_day_end")
+	}
+	return loc.T("free.until", b.StartString(), b.Period)
+}
+
+// AudienceDetails describes capacity, kind and equipment of the audience, empty if they are unknown.
+func AudienceDetails(loc i18n.Localizer, aud service.Audience) string {
+	parts := make([]string, 0, 2+len(aud.Equipment))
+	if aud.Kind != nil {
+		parts = append(parts, loc.T("kind."+*aud.Kind))
+	}
+	if aud.Capacity != nil {
+		parts = append(parts, loc.T("audience.capacity", *aud.Capacity))
+	}
+	for _, e := range aud.Equipment {
+		parts = append(parts, loc.T("equipment."+e))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " · " + strings.Join(parts, ", ")
+}
+
+func AudienceName(aud service.Audience) string {
+	name := aud.Number
+	if aud.Suffix != nil {
+		name += *aud.Suffix
+	}
+	return name
+}
+
+// ReportMark flags an audience users reported as occupied or locked.
+func ReportMark(loc i18n.Localizer, aud service.EmptyAudience) string {
+	if !aud.Suspicious() {
+		return ""
+	}
+	return loc.T("report.mark", aud.UnavailableConfidence*100)
+}
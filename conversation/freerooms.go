@@ -0,0 +1,361 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// The free room query of /start stores its step in button data, e.g. "q:d:Monday",
+// so buttons do not depend on the language of the question.
+const (
+	queryCallbackPrefix = "q:"
+
+	queryStepWeekDay    = "d"
+	queryStepWeekType   = "t"
+	queryStepBuilding   = "b"
+	queryStepFloor      = "f"
+	queryStepPeriod     = "p"
+	queryStepLastPeriod = "l"
+
+	// anyChoice is data of "любой корпус" and "любой этаж" buttons
+	anyChoice = "*"
+)
+
+func queryButton(text, step, value string) Button {
+	return Button{Text: text, Data: queryCallbackPrefix + step + ":" + value}
+}
+
+func column(buttons ...Button) [][]Button {
+	return rows(buttons, 1)
+}
+
+// rows lays the buttons out perRow in a row.
+func rows(buttons []Button, perRow int) [][]Button {
+	res := make([][]Button, 0, (len(buttons)+perRow-1)/perRow)
+	for len(buttons) > perRow {
+		res = append(res, buttons[:perRow:perRow])
+		buttons = buttons[perRow:]
+	}
+	if len(buttons) > 0 {
+		res = append(res, buttons)
+	}
+	return res
+}
+
+func weekDayButtons(loc i18n.Localizer) [][]Button {
+	buttons := make([]Button, 0, len(service.WeekDays()))
+	for _, wd := range service.WeekDays() {
+		buttons = append(buttons, queryButton(loc.WeekDay(wd), queryStepWeekDay, wd))
+	}
+	return column(buttons...)
+}
+
+func weekTypeButtons(loc i18n.Localizer) [][]Button {
+	return column(
+		queryButton(loc.WeekType(service.WeekTypeNumerator), queryStepWeekType, service.WeekTypeNumerator),
+		queryButton(loc.WeekType(service.WeekTypeDenominator), queryStepWeekType, service.WeekTypeDenominator),
+	)
+}
+
+// withPreferred puts the user's default choice first and marks it with a star.
+func withPreferred(buttons []Button, preferred func(Button) bool) [][]Button {
+	for i, b := range buttons {
+		if preferred(b) {
+			b.Text = "★ " + b.Text
+			buttons = append([]Button{b}, append(buttons[:i:i], buttons[i+1:]...)...)
+			break
+		}
+	}
+	return column(buttons...)
+}
+
+func buildingButtons(loc i18n.Localizer, buildings []service.Building, preferred *string) [][]Button {
+	buttons := make([]Button, 0, len(buildings)+1)
+	for _, b := range buildings {
+		buttons = append(buttons, queryButton(loc.T("query.building_rooms", loc.Building(b.Name), b.Audiences), queryStepBuilding, b.Name))
+	}
+	buttons = append(buttons, queryButton(loc.T("query.any_building"), queryStepBuilding, anyChoice))
+	return withPreferred(buttons, func(b Button) bool {
+		return preferred != nil && b.Data == queryCallbackPrefix+queryStepBuilding+":"+*preferred
+	})
+}
+
+// mergeFloors sums floors of different buildings with the same number, for "любой корпус".
+func mergeFloors(floors []service.Floor) []service.Floor {
+	byNumber := make(map[int]int)
+	res := make([]service.Floor, 0, len(floors))
+	for _, f := range floors {
+		if i, ok := byNumber[f.Floor]; ok {
+			res[i].Audiences += f.Audiences
+			continue
+		}
+		byNumber[f.Floor] = len(res)
+		res = append(res, service.Floor{Floor: f.Floor, Audiences: f.Audiences})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Floor < res[j].Floor })
+	return res
+}
+
+// floorButtons offers floors which have audiences, free maps floors to the number of audiences
+// free all day and is nil if the counts are not shown.
+func floorButtons(loc i18n.Localizer, floors []service.Floor, free map[int]int, preferred *int) [][]Button {
+	buttons := make([]Button, 0, len(floors)+1)
+	for _, f := range floors {
+		text := loc.T("query.floor_rooms", f.Floor, f.Audiences)
+		if free != nil {
+			text = loc.T("query.floor_rooms_free", f.Floor, f.Audiences, free[f.Floor])
+		}
+		buttons = append(buttons, queryButton(text, queryStepFloor, strconv.Itoa(f.Floor)))
+	}
+	buttons = append(buttons, queryButton(loc.T("query.any_floor"), queryStepFloor, anyChoice))
+	return withPreferred(buttons, func(b Button) bool {
+		return preferred != nil && b.Data == queryCallbackPrefix+queryStepFloor+":"+strconv.Itoa(*preferred)
+	})
+}
+
+// freeAllDay counts audiences free for every period of the chosen day by floor.
+func (c *Core) freeAllDay(ctx context.Context, filter service.EmptyAudiencesFilter) (map[int]int, error) {
+	filter.Periods = make([]int, 0, len(service.Bells))
+	for _, b := range service.Bells {
+		filter.Periods = append(filter.Periods, b.Period)
+	}
+	auds, err := c.srvc.ListEmptyAudiences(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]int)
+	for _, aud := range auds {
+		res[aud.Floor]++
+	}
+	return res, nil
+}
+
+func periodButtons() [][]Button {
+	buttons := make([]Button, 0, len(service.Bells))
+	for _, b := range service.Bells {
+		buttons = append(buttons, queryButton(strconv.Itoa(b.Period), queryStepPeriod, strconv.Itoa(b.Period)))
+	}
+	return column(buttons...)
+}
+
+// lastPeriodButtons offers the end of a period range starting at first.
+func lastPeriodButtons(loc i18n.Localizer, first int) [][]Button {
+	buttons := make([]Button, 0, len(service.Bells))
+	for _, b := range service.Bells {
+		if b.Period < first {
+			continue
+		}
+		text := strconv.Itoa(b.Period)
+		if b.Period == first {
+			text = loc.T("query.only_period", b.Period)
+		}
+		buttons = append(buttons, queryButton(text, queryStepLastPeriod, strconv.Itoa(b.Period)))
+	}
+	return column(buttons...)
+}
+
+func (c *Core) startQuery(loc i18n.Localizer, chatID string) []Reply {
+	c.sessions.set(chatID, service.EmptyAudiencesFilter{})
+	return []Reply{{Text: loc.T("query.week_day"), Buttons: weekDayButtons(loc)}}
+}
+
+// handleQueryAnswer stores the answer to one question of the free room query and asks the next one.
+func (c *Core) handleQueryAnswer(ctx context.Context, loc i18n.Localizer, msg Message) ([]Reply, error) {
+	step, value, found := strings.Cut(strings.TrimPrefix(msg.Data, queryCallbackPrefix), ":")
+	if !strings.HasPrefix(msg.Data, queryCallbackPrefix) || !found {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("invalid query data %q", msg.Data))
+	}
+
+	filter := c.sessions.get(msg.ChatID)
+	ask := func(text string, buttons [][]Button) ([]Reply, error) {
+		c.sessions.set(msg.ChatID, filter)
+		return []Reply{{Text: text, Buttons: buttons, Edit: true}}, nil
+	}
+
+	switch step {
+	case queryStepWeekDay:
+		filter.WeekDays = []string{value}
+		return ask(loc.T("query.week_type"), weekTypeButtons(loc))
+	case queryStepWeekType:
+		filter.WeekType = value
+		buildings, err := c.srvc.ListBuildings(ctx)
+		if err != nil {
+			return nil, NewUserError(loc.T("error.retry_start"), fmt.Errorf("cannot list buildings: %w", err))
+		}
+		prefs := c.preferences(ctx, msg.UserID)
+		return ask(loc.T("query.building"), buildingButtons(loc, buildings, prefs.DefaultBuilding))
+	case queryStepBuilding:
+		filter.Buildings = nil
+		if value != anyChoice {
+			filter.Buildings = []string{value}
+		}
+		floors, err := c.srvc.ListFloors(ctx, filter.Buildings...)
+		if err != nil {
+			return nil, NewUserError(loc.T("error.retry_start"), fmt.Errorf("cannot list floors: %w", err))
+		}
+		var free map[int]int
+		if c.floorFreeCounts {
+			if free, err = c.freeAllDay(ctx, filter); err != nil {
+				c.logger.WithError(err).Error("cannot count free audiences by floor")
+			}
+		}
+		prefs := c.preferences(ctx, msg.UserID)
+		return ask(loc.T("query.floor"), floorButtons(loc, mergeFloors(floors), free, prefs.DefaultFloor))
+	case queryStepFloor:
+		filter.Floors = nil
+		if value != anyChoice {
+			floor, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, NewUserError(loc.T("error.bad_choice"), fmt.Errorf("cannot convert floor: %w", err))
+			}
+			filter.Floors = []int{floor}
+		}
+		return ask(loc.T("query.period"), periodButtons())
+	case queryStepPeriod:
+		period, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, NewUserError(loc.T("error.bad_choice"), fmt.Errorf("cannot convert period: %w", err))
+		}
+		filter.Periods = []int{period}
+		return ask(loc.T("query.last_period"), lastPeriodButtons(loc, period))
+	case queryStepLastPeriod:
+		last, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, NewUserError(loc.T("error.bad_choice"), fmt.Errorf("cannot convert period: %w", err))
+		}
+		if len(filter.Periods) == 0 {
+			c.logger.WithField("chat", msg.ChatID).Warning("no first period in query")
+			return c.startQuery(loc, msg.ChatID), nil
+		}
+		periods := service.PeriodRange(filter.Periods[0], last)
+		if len(periods) == 0 {
+			return nil, NewUserError(loc.T("error.bad_choice"), fmt.Errorf("invalid periods %d-%d", filter.Periods[0], last))
+		}
+		filter.Periods = periods
+		return c.finishQuery(ctx, loc, msg.ChatID, msg.UserID, filter)
+	default:
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("unknown query step %q", msg.Data))
+	}
+}
+
+// finishQuery answers the query and starts a new one.
+func (c *Core) finishQuery(ctx context.Context, loc i18n.Localizer, chatID, userID string, filter service.EmptyAudiencesFilter) ([]Reply, error) {
+	result, err := c.emptyAudiencesReply(ctx, loc, &filter, "", userID)
+	if err != nil {
+		return nil, err
+	}
+	c.sessions.delete(chatID)
+	return append([]Reply{result}, c.startQuery(loc, chatID)...), nil
+}
+
+// emptyAudiencesReply lists free audiences grouped by floor, long lists are split into pages.
+// Rooms of the current period get report buttons if userID is set.
+func (c *Core) emptyAudiencesReply(ctx context.Context, loc i18n.Localizer, filter *service.EmptyAudiencesFilter, header, userID string) (Reply, error) {
+	auds, err := c.srvc.ListEmptyAudiences(ctx, filter)
+	if err != nil {
+		return Reply{}, NewUserError(header+loc.T("error.retry_start"), fmt.Errorf("cannot list empty audiences: %w", err))
+	}
+	if len(auds) == 0 {
+		return Reply{Text: header + loc.T("free.none")}, nil
+	}
+
+	free, claimed := make([]service.EmptyAudience, 0, len(auds)), make([]service.EmptyAudience, 0)
+	for _, aud := range auds {
+		if aud.ClaimedUntil != nil {
+			claimed = append(claimed, aud)
+		} else {
+			free = append(free, aud)
+		}
+	}
+
+	// rooms can be reported only while the result covers the current period
+	slot := c.calendar.CurrentSlot(time.Now())
+	reportable := userID != "" && filter.Covers(slot)
+
+	sections := make([]section, 0)
+	for _, group := range service.GroupByFloor(free) {
+		s := section{title: loc.T("free.floor", loc.Building(group.Building), group.Floor)}
+		for _, aud := range group.Audiences {
+			s.lines = append(s.lines, AudienceName(aud.Audience)+" — "+FreeUntil(loc, aud)+AudienceDetails(loc, aud.Audience)+ReportMark(loc, aud))
+			if reportable {
+				s.buttons = append(s.buttons, reportButton(loc, aud.Audience, slot.Period))
+			}
+		}
+		sections = append(sections, s)
+	}
+	if len(claimed) > 0 {
+		s := section{title: loc.T("free.claimed")}
+		for _, aud := range claimed {
+			s.lines = append(s.lines, loc.T("free.claimed_line", AudienceName(aud.Audience), loc.Building(aud.Building),
+				aud.ClaimedUntil.In(service.MoscowLocation).Format("15:04")))
+		}
+		sections = append(sections, s)
+	}
+
+	pages := paginate(sections, c.pageSize)
+	for i := range pages {
+		pages[i].text = header + loc.T("free.header", PeriodsDescription(loc, filter.Periods)) + pages[i].text
+	}
+	if reportable {
+		pages[len(pages)-1].text += "\n\n" + loc.T("report.buttons")
+	}
+	id := ""
+	if len(pages) > 1 {
+		id = c.pages.put(pages, time.Now())
+	}
+	return pageReply(loc, id, pages, 0, false), nil
+}
+
+func (c *Core) handleNow(ctx context.Context, loc i18n.Localizer, msg Message) ([]Reply, error) {
+	buildings, err := c.KnownBuildings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	args, err := parseNowArgs(msg.Args, buildings)
+	if err != nil {
+		return []Reply{{Text: loc.T("now.usage")}}, nil
+	}
+
+	now := time.Now()
+	slot := c.calendar.CurrentSlot(now)
+	header := SlotDescription(loc, slot, now)
+
+	filter := service.EmptyAudiencesFilter{
+		WeekType:    slot.WeekType,
+		WeekDays:    []string{slot.WeekDay},
+		Periods:     []int{slot.Period},
+		ClaimsAt:    &now,
+		MinCapacity: args.minCapacity,
+		Kinds:       args.kinds,
+		Equipment:   args.equipment,
+	}
+	building, floor := args.building, args.floor
+	if building == "" && floor == 0 {
+		prefs := c.preferences(ctx, msg.UserID)
+		if prefs.DefaultBuilding != nil {
+			building = *prefs.DefaultBuilding
+		}
+		if prefs.DefaultFloor != nil {
+			floor = *prefs.DefaultFloor
+		}
+	}
+	if building != "" {
+		filter.Buildings = []string{building}
+	}
+	if floor != 0 {
+		filter.Floors = []int{floor}
+	}
+
+	result, err := c.emptyAudiencesReply(ctx, loc, &filter, header, msg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return []Reply{result}, nil
+}
@@ -0,0 +1,169 @@
+package conversation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+)
+
+const (
+	// pageCallbackPrefix is data of the page navigation buttons, "pg:<result id>:<page>"
+	pageCallbackPrefix = "pg:"
+
+	// DefaultPageSize is how many rooms a page of results shows if the size is not configured
+	DefaultPageSize = 30
+	// maxPageLength keeps a page within the 4096 characters telegram allows, with room for the header
+	maxPageLength = 3500
+
+	// pagesTTL is how long the pages of a result can be flipped through
+	pagesTTL = time.Hour
+	// maxCachedResults bounds the memory taken by pages, the oldest results go first
+	maxCachedResults = 1000
+
+	// buttonsPerRow keeps the buttons of a page short enough to show room names
+	buttonsPerRow = 3
+)
+
+// section is a heading with lines under it, e.g. a floor with its free rooms.
+type section struct {
+	title string
+	lines []string
+	// buttons go with the lines of the same index to the page, nil if the lines have none
+	buttons []Button
+}
+
+// page is one message of a long result, buttons act on the lines it shows.
+type page struct {
+	text    string
+	buttons []Button
+}
+
+// paginate lays the sections out on pages of at most pageSize lines, a section split between
+// pages repeats its title.
+func paginate(sections []section, pageSize int) []page {
+	var (
+		pages   []page
+		text    strings.Builder
+		buttons []Button
+		lines   int
+		current string
+	)
+	flush := func() {
+		if text.Len() > 0 {
+			pages = append(pages, page{text: text.String(), buttons: buttons})
+		}
+		text.Reset()
+		buttons = nil
+		lines = 0
+		current = ""
+	}
+
+	for _, s := range sections {
+		for i, line := range s.lines {
+			if lines >= pageSize || text.Len()+len(s.title)+len(line)+4 > maxPageLength {
+				flush()
+			}
+			if current != s.title {
+				text.WriteString("\n\n" + s.title)
+				current = s.title
+			}
+			text.WriteString("\n" + line)
+			if i < len(s.buttons) {
+				buttons = append(buttons, s.buttons[i])
+			}
+			lines++
+		}
+	}
+	flush()
+	return pages
+}
+
+type cachedPages struct {
+	pages   []page
+	created time.Time
+}
+
+// pageCache keeps rendered results, so flipping pages does not query the database again.
+type pageCache struct {
+	mu      sync.Mutex
+	results map[string]cachedPages
+}
+
+func newPageCache() *pageCache {
+	return &pageCache{results: make(map[string]cachedPages)}
+}
+
+func (c *pageCache) put(pages []page, now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	oldestID, oldest := "", now
+	for id, r := range c.results {
+		if now.Sub(r.created) > pagesTTL {
+			delete(c.results, id)
+			continue
+		}
+		if r.created.Before(oldest) {
+			oldestID, oldest = id, r.created
+		}
+	}
+	if len(c.results) >= maxCachedResults {
+		delete(c.results, oldestID)
+	}
+
+	id := uuid.NewString()
+	c.results[id] = cachedPages{pages: pages, created: now}
+	return id
+}
+
+func (c *pageCache) get(id string, now time.Time) ([]page, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.results[id]
+	if !ok || now.Sub(r.created) > pagesTTL {
+		return nil, false
+	}
+	return r.pages, true
+}
+
+// pageReply shows one page of a cached result with its buttons and ‹ › buttons to its neighbours.
+func pageReply(loc i18n.Localizer, id string, pages []page, n int, edit bool) Reply {
+	r := Reply{Text: pages[n].text, Buttons: rows(pages[n].buttons, buttonsPerRow), Edit: edit}
+	if len(pages) == 1 {
+		return r
+	}
+
+	r.Text += "\n\n" + loc.T("page.counter", n+1, len(pages))
+	row := make([]Button, 0, 2)
+	if n > 0 {
+		row = append(row, Button{Text: "‹", Data: pageCallbackPrefix + id + ":" + strconv.Itoa(n-1)})
+	}
+	if n < len(pages)-1 {
+		row = append(row, Button{Text: "›", Data: pageCallbackPrefix + id + ":" + strconv.Itoa(n+1)})
+	}
+	r.Buttons = append(r.Buttons, row)
+	return r
+}
+
+func (c *Core) handlePage(loc i18n.Localizer, data string) ([]Reply, error) {
+	id, pageArg, found := strings.Cut(strings.TrimPrefix(data, pageCallbackPrefix), ":")
+	n, err := strconv.Atoi(pageArg)
+	if !found || err != nil {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("invalid page data %q", data))
+	}
+
+	pages, ok := c.pages.get(id, time.Now())
+	if !ok {
+		return nil, NewUserError(loc.T("page.expired"), fmt.Errorf("no cached pages %q", id))
+	}
+	if n < 0 || n >= len(pages) {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("page %d out of %d", n, len(pages)))
+	}
+	return []Reply{pageReply(loc, id, pages, n, true)}, nil
+}
@@ -0,0 +1,142 @@
+package conversation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPaginate(t *testing.T) {
+	long := func(c string) string {
+		return strings.Repeat(c, 1000)
+	}
+	button := func(data string) Button {
+		return Button{Text: data, Data: data}
+	}
+
+	tests := []struct {
+		name     string
+		sections []section
+		pageSize int
+		want     []page
+	}{
+		{
+			name:     "no sections",
+			pageSize: 2,
+		},
+		{
+			name:     "sections on one page",
+			sections: []section{{title: "F1", lines: []string{"a"}}, {title: "F2", lines: []string{"b"}}},
+			pageSize: 2,
+			want:     []page{{text: "\n\nF1\na\n\nF2\nb"}},
+		},
+		{
+			name:     "split by page size repeats the title",
+			sections: []section{{title: "F1", lines: []string{"a", "b", "c"}}},
+			pageSize: 2,
+			want:     []page{{text: "\n\nF1\na\nb"}, {text: "\n\nF1\nc"}},
+		},
+		{
+			name: "buttons follow their lines",
+			sections: []section{
+				{title: "F1", lines: []string{"a", "b"}, buttons: []Button{button("a"), button("b")}},
+				{title: "F2", lines: []string{"c"}, buttons: []Button{button("c")}},
+			},
+			pageSize: 2,
+			want: []page{
+				{text: "\n\nF1\na\nb", buttons: []Button{button("a"), button("b")}},
+				{text: "\n\nF2\nc", buttons: []Button{button("c")}},
+			},
+		},
+		{
+			name:     "split by length",
+			sections: []section{{title: "F1", lines: []string{long("a"), long("b"), long("c"), long("d")}}},
+			pageSize: DefaultPageSize,
+			want: []page{
+				{text: "\n\nF1\n" + long("a") + "\n" + long("b") + "\n" + long("c")},
+				{text: "\n\nF1\n" + long("d")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := paginate(tt.sections, tt.pageSize)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("paginate() = %q, want %q", got, tt.want)
+			}
+			for i, p := range got {
+				if len(p.text) > maxPageLength {
+					t.Errorf("page %d is %d bytes long, the limit is %d", i, len(p.text), maxPageLength)
+				}
+			}
+		})
+	}
+}
+
+func TestPageCacheTTL(t *testing.T) {
+	created := time.Date(2026, time.September, 7, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		found bool
+	}{
+		{"just put", 0, true},
+		{"within ttl", pagesTTL - time.Second, true},
+		{"at ttl", pagesTTL, true},
+		{"expired", pagesTTL + time.Second, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newPageCache()
+			pages := []page{{text: "a"}, {text: "b"}}
+			id := c.put(pages, created)
+
+			got, ok := c.get(id, created.Add(tt.after))
+			if ok != tt.found {
+				t.Fatalf("get() found = %v, want %v", ok, tt.found)
+			}
+			if ok && !reflect.DeepEqual(got, pages) {
+				t.Errorf("get() = %q, want %q", got, pages)
+			}
+		})
+	}
+
+	if _, ok := newPageCache().get("unknown", created); ok {
+		t.Error("get() found a result that was never put")
+	}
+}
+
+func TestPageCacheEviction(t *testing.T) {
+	start := time.Date(2026, time.September, 7, 10, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time {
+		return start.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	c := newPageCache()
+	ids := make([]string, maxCachedResults)
+	for i := range ids {
+		ids[i] = c.put([]page{{text: "p"}}, at(i))
+	}
+
+	last := c.put([]page{{text: "last"}}, at(maxCachedResults))
+	if len(c.results) != maxCachedResults {
+		t.Errorf("cache holds %d results, want %d", len(c.results), maxCachedResults)
+	}
+	if _, ok := c.get(ids[0], at(maxCachedResults)); ok {
+		t.Error("the oldest result is not evicted")
+	}
+	for _, id := range []string{ids[1], ids[maxCachedResults-1], last} {
+		if _, ok := c.get(id, at(maxCachedResults)); !ok {
+			t.Errorf("result %s is evicted, only the oldest one should be", id)
+		}
+	}
+
+	// expired results go before the oldest live one
+	later := at(maxCachedResults).Add(pagesTTL + time.Second)
+	c.put([]page{{text: "later"}}, later)
+	if len(c.results) != 1 {
+		t.Errorf("cache holds %d results after they expired, want 1", len(c.results))
+	}
+}
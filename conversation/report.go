@@ -0,0 +1,76 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// Report buttons of free room results carry the audience and the period they were shown for,
+// "rp:<audience id>:<period>" asks what is going on in the room and "rp:<audience id>:<period>:<state>"
+// stores the answer, states are service.ReportState*.
+const reportCallbackPrefix = "rp:"
+
+var reportStates = []string{service.ReportStateOccupied, service.ReportStateLocked, service.ReportStateFree}
+
+func reportButton(loc i18n.Localizer, aud service.Audience, period int) Button {
+	return Button{Text: loc.T("report.button", AudienceName(aud)), Data: reportCallbackPrefix + aud.ID + ":" + strconv.Itoa(period)}
+}
+
+// handleReport asks for the state of the room, or stores it if it has been chosen.
+func (c *Core) handleReport(ctx context.Context, loc i18n.Localizer, msg Message) ([]Reply, error) {
+	parts := strings.Split(strings.TrimPrefix(msg.Data, reportCallbackPrefix), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("invalid report data %q", msg.Data))
+	}
+	period, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("cannot convert period: %w", err))
+	}
+	slot := c.calendar.CurrentSlot(time.Now())
+	if period != slot.Period {
+		return nil, NewUserError(loc.T("report.expired"), fmt.Errorf("report for period %d during %d", period, slot.Period))
+	}
+	if msg.UserID == "" {
+		return nil, NewUserError(loc.T("error.generic"), errors.New("report without a user"))
+	}
+
+	aud, err := c.srvc.GetAudience(ctx, parts[0])
+	if errors.Is(err, service.ErrorNotFound) {
+		return nil, NewUserError(loc.T("error.stale_button"), fmt.Errorf("cannot get audience: %w", err))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get audience: %w", err)
+	}
+
+	if len(parts) == 2 {
+		buttons := make([]Button, 0, len(reportStates))
+		for _, state := range reportStates {
+			buttons = append(buttons, Button{Text: loc.T("report." + state), Data: msg.Data + ":" + state})
+		}
+		return []Reply{{Text: loc.T("report.ask", AudienceName(aud), slot.Period), Buttons: column(buttons...)}}, nil
+	}
+
+	err = c.srvc.ReportRoom(ctx, &service.RoomReport{
+		UserID:     msg.UserID,
+		AudienceID: aud.ID,
+		WeekType:   slot.WeekType,
+		WeekDay:    slot.WeekDay,
+		Period:     slot.Period,
+		State:      parts[2],
+	})
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return nil, NewUserError(loc.T("error.stale_button"), err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot save report: %w", err)
+	}
+	return []Reply{{Text: loc.T("report.thanks", AudienceName(aud), slot.Period), Edit: true}}, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgtype"
 
 	"github.com/AlexisOMG/bmstu-free-rooms/service"
 )
@@ -17,6 +19,9 @@ var (
 		"building",
 		"floor",
 		"suffix",
+		"capacity",
+		"kind",
+		"equipment",
 	}
 )
 
@@ -29,20 +34,26 @@ func withPrefix(fields []string, prefix string) []string {
 }
 
 type audience struct {
-	ID       string  `db:"id"`
-	Number   string  `db:"number"`
-	Building string  `db:"building"`
-	Floor    int     `db:"floor"`
-	Suffix   *string `db:"suffix"`
+	ID        string           `db:"id"`
+	Number    string           `db:"number"`
+	Building  string           `db:"building"`
+	Floor     int              `db:"floor"`
+	Suffix    *string          `db:"suffix"`
+	Capacity  *int             `db:"capacity"`
+	Kind      *string          `db:"kind"`
+	Equipment pgtype.TextArray `db:"equipment"`
 }
 
 func (a *audience) toService() service.Audience {
 	return service.Audience{
-		ID:       a.ID,
-		Number:   a.Number,
-		Building: a.Building,
-		Floor:    a.Floor,
-		Suffix:   a.Suffix,
+		ID:        a.ID,
+		Number:    a.Number,
+		Building:  a.Building,
+		Floor:     a.Floor,
+		Suffix:    a.Suffix,
+		Capacity:  a.Capacity,
+		Kind:      a.Kind,
+		Equipment: fromTextArray(a.Equipment),
 	}
 }
 
@@ -53,19 +64,43 @@ func (a *audience) values() []interface{} {
 		a.Building,
 		a.Floor,
 		a.Suffix,
+		a.Capacity,
+		a.Kind,
+		a.Equipment,
 	}
 }
 
 func audienceToDB(a service.Audience) audience {
 	return audience{
-		ID:       a.ID,
-		Number:   a.Number,
-		Building: a.Building,
-		Floor:    a.Floor,
-		Suffix:   a.Suffix,
+		ID:        a.ID,
+		Number:    a.Number,
+		Building:  a.Building,
+		Floor:     a.Floor,
+		Suffix:    a.Suffix,
+		Capacity:  a.Capacity,
+		Kind:      a.Kind,
+		Equipment: toTextArray(a.Equipment),
 	}
 }
 
+// toTextArray stores nil as an empty array, equipment is never NULL.
+func toTextArray(values []string) pgtype.TextArray {
+	arr := pgtype.TextArray{}
+	if values == nil {
+		values = []string{}
+	}
+	_ = arr.Set(values)
+	return arr
+}
+
+func fromTextArray(arr pgtype.TextArray) []string {
+	var res []string
+	if arr.Status == pgtype.Present {
+		_ = arr.AssignTo(&res)
+	}
+	return res
+}
+
 func audiencesToService(audiences []audience) []service.Audience {
 	res := make([]service.Audience, 0, len(audiences))
 	for _, aud := range audiences {
@@ -97,7 +132,7 @@ func (d *Database) SaveAudiences(ctx context.Context, audiences ...service.Audie
 	}
 
 	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, audienceTable, err)
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, audienceTable, markTransient(err))
 	}
 
 	return nil
@@ -108,12 +143,92 @@ func (d *Database) ListAudienceByNumber(ctx context.Context, number string, suff
 	query := squirrel.Select(append([]string{"id"}, audiencesFieldNames...)...).
 		From(audienceTable).PlaceholderFormat(squirrel.Dollar)
 	query = query.Where(squirrel.Eq{"number": number})
+	// a bare number means the room without a suffix, not any of 395, 395ю and 395а
 	if suffix != nil {
-		query = query.Where(squirrel.Eq{"suffix": suffix})
+		query = query.Where(squirrel.Eq{"suffix": *suffix})
+	} else {
+		query = query.Where(squirrel.Eq{"suffix": nil})
+	}
+
+	sqlText, bound, err := query.ToSql()
+
+	if err != nil {
+		return service.Audience{}, fmt.Errorf("failed to build selection %v SQL: %w", audienceTable, err)
+	}
+
+	if err = d.db.GetContext(ctx, &res, sqlText, bound...); err != nil {
+		return service.Audience{}, mapErrors(err, "cannot select "+audienceTable+": %w")
+	}
+
+	return res.toService(), nil
+}
+
+func (d *Database) SaveAudienceAttributes(ctx context.Context, attrs *service.AudienceAttributes) error {
+	query := squirrel.Update(audienceTable).
+		Set("capacity", attrs.Capacity).
+		Set("kind", attrs.Kind).
+		Set("equipment", toTextArray(attrs.Equipment)).
+		Where(squirrel.Eq{"id": attrs.AudienceID}).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	res, err := d.db.ExecContext(ctx, sql, bound...)
+	if err != nil {
+		return fmt.Errorf("cannot update query: %v, args %v, in %v: %w", query, bound, audienceTable, markTransient(err))
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("cannot update %v %s: %w", audienceTable, attrs.AudienceID, service.ErrorNotFound)
+	}
+
+	return nil
+}
+
+type floor struct {
+	Building  string `db:"building"`
+	Floor     int    `db:"floor"`
+	Audiences int    `db:"audiences"`
+}
+
+func (d *Database) ListFloors(ctx context.Context, buildings []string) ([]service.Floor, error) {
+	res := []floor{}
+	query := squirrel.Select("building", "floor", "count(*) AS audiences").
+		From(audienceTable).
+		GroupBy("building", "floor").
+		OrderBy("building", "floor").PlaceholderFormat(squirrel.Dollar)
+	if len(buildings) > 0 {
+		query = query.Where(squirrel.Eq{"building": buildings})
 	}
 
 	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.Floor{}, fmt.Errorf("failed to build selection %v SQL: %w", audienceTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.Floor{}, mapErrors(err, "cannot select "+audienceTable+": %w")
+	}
 
+	floors := make([]service.Floor, 0, len(res))
+	for _, f := range res {
+		floors = append(floors, service.Floor{
+			Building:  f.Building,
+			Floor:     f.Floor,
+			Audiences: f.Audiences,
+		})
+	}
+	return floors, nil
+}
+
+func (d *Database) GetAudience(ctx context.Context, id string) (service.Audience, error) {
+	res := audience{}
+	query := squirrel.Select(append([]string{"id"}, audiencesFieldNames...)...).
+		From(audienceTable).
+		Where(squirrel.Eq{"id": id}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
 	if err != nil {
 		return service.Audience{}, fmt.Errorf("failed to build selection %v SQL: %w", audienceTable, err)
 	}
@@ -125,24 +240,108 @@ func (d *Database) ListAudienceByNumber(ctx context.Context, number string, suff
 	return res.toService(), nil
 }
 
-func (d *Database) ListEmptyAudiences(ctx context.Context, filters *service.EmptyAudiencesFilter) ([]service.Audience, error) {
-	res := []audience{}
+type emptyAudience struct {
+	audience
+	NextBusyPeriod *int       `db:"next_busy_period"`
+	ClaimedUntil   *time.Time `db:"claimed_until"`
+}
+
+func (a *emptyAudience) toService() service.EmptyAudience {
+	res := service.EmptyAudience{
+		Audience:     a.audience.toService(),
+		ClaimedUntil: a.ClaimedUntil,
+	}
+	if a.NextBusyPeriod != nil {
+		res.NextBusyPeriod = *a.NextBusyPeriod
+	}
+	return res
+}
+
+func emptyAudiencesToService(audiences []emptyAudience) []service.EmptyAudience {
+	res := make([]service.EmptyAudience, 0, len(audiences))
+	for i := range audiences {
+		res = append(res, audiences[i].toService())
+	}
+	return res
+}
+
+func (d *Database) ListEmptyAudiences(ctx context.Context, filters *service.EmptyAudiencesFilter) ([]service.EmptyAudience, error) {
+	res := []emptyAudience{}
+
+	busy := squirrel.Select("1").
+		From(scheduleTable + " t").
+		Where("t.audience_id = a.id").
+		Where(squirrel.Eq{
+			"t.week_type": filters.WeekType,
+			"t.period":    filters.Periods,
+		})
+	if len(filters.WeekDays) > 0 {
+		busy = busy.Where(squirrel.Eq{"t.week_day": filters.WeekDays})
+	}
+	busySQL, busyArgs, err := busy.ToSql()
+	if err != nil {
+		return []service.EmptyAudience{}, err
+	}
+
+	nextBusy := squirrel.Select("min(n.period)").
+		From(scheduleTable + " n").
+		Where("n.audience_id = a.id").
+		Where(squirrel.Eq{"n.week_type": filters.WeekType}).
+		Where(squirrel.Gt{"n.period": filters.LastPeriod()})
+	if len(filters.WeekDays) > 0 {
+		nextBusy = nextBusy.Where(squirrel.Eq{"n.week_day": filters.WeekDays})
+	}
+	nextBusySQL, nextBusyArgs, err := nextBusy.ToSql()
+	if err != nil {
+		return []service.EmptyAudience{}, err
+	}
 
 	query := squirrel.Select(withPrefix(append([]string{"id"}, audiencesFieldNames...), "a")...).
-		From(audienceTable+" a").
-		LeftJoin(`schedule t on t.week_type = ? and t.week_day = ? and t.period = ? and t.audience_id = a.id`,
-			filters.WeekType, filters.WeekDay, filters.Period).
-		Where(squirrel.Eq{"t.audience_id": nil}).
-		Where(squirrel.Eq{"a.building": filters.Building}).
-		Where(squirrel.Eq{"a.floor": filters.Floor}).PlaceholderFormat(squirrel.Dollar)
+		Column("("+nextBusySQL+") AS next_busy_period", nextBusyArgs...)
+	if filters.ClaimsAt != nil {
+		claimed := squirrel.Select("max(c.expires_at)").
+			From(claimTable + " c").
+			Where("c.audience_id = a.id").
+			Where(squirrel.LtOrEq{"c.claimed_at": *filters.ClaimsAt}).
+			Where(squirrel.Gt{"c.expires_at": *filters.ClaimsAt})
+		claimedSQL, claimedArgs, err := claimed.ToSql()
+		if err != nil {
+			return []service.EmptyAudience{}, err
+		}
+		query = query.Column("("+claimedSQL+") AS claimed_until", claimedArgs...)
+		if filters.HideClaimed {
+			query = query.Where("("+claimedSQL+") IS NULL", claimedArgs...)
+		}
+	} else {
+		query = query.Column("NULL::timestamptz AS claimed_until")
+	}
+	query = query.From(audienceTable+" a").
+		Where("NOT EXISTS ("+busySQL+")", busyArgs...).
+		OrderBy("a.building", "a.floor", "length(a.number)", "a.number", "a.suffix").
+		PlaceholderFormat(squirrel.Dollar)
+	if len(filters.Buildings) > 0 {
+		query = query.Where(squirrel.Eq{"a.building": filters.Buildings})
+	}
+	if len(filters.Floors) > 0 {
+		query = query.Where(squirrel.Eq{"a.floor": filters.Floors})
+	}
+	if filters.MinCapacity > 0 {
+		query = query.Where(squirrel.GtOrEq{"a.capacity": filters.MinCapacity})
+	}
+	if len(filters.Kinds) > 0 {
+		query = query.Where(squirrel.Eq{"a.kind": filters.Kinds})
+	}
+	if len(filters.Equipment) > 0 {
+		query = query.Where("a.equipment @> ?", toTextArray(filters.Equipment))
+	}
 
 	sqlText, bound, err := query.ToSql()
 	if err != nil {
-		return []service.Audience{}, err
+		return []service.EmptyAudience{}, err
 	}
 
 	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
-		return []service.Audience{}, mapErrors(err, "cannot select "+audienceTable+": %w")
+		return []service.EmptyAudience{}, mapErrors(err, "cannot select "+audienceTable+": %w")
 	}
-	return audiencesToService(res), nil
+	return emptyAudiencesToService(res), nil
 }
@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// claimLockClass is the first key of advisory locks on claims of a user, the second one is the hash of the user ID
+const claimLockClass = 1
+
+var (
+	claimTable       = "room_claim"
+	claimsFieldNames = []string{
+		"user_id",
+		"audience_id",
+		"claimed_at",
+		"expires_at",
+	}
+)
+
+// SaveClaim saves the claim unless the user already holds maxActive other claims active at its
+// start. The count runs under a transaction advisory lock keyed on the user, it needs no row to
+// exist, so concurrent claims of one user cannot both slip under the cap.
+func (d *Database) SaveClaim(ctx context.Context, claim *service.Claim, maxActive int) (bool, error) {
+	saved := false
+	err := d.inTx(ctx, func(tx *sqlx.Tx) error {
+		saved = false
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, hashtext($2))", claimLockClass, claim.UserID); err != nil {
+			return fmt.Errorf("cannot lock claims of user %v: %w", claim.UserID, markTransient(err))
+		}
+
+		count, err := countActiveClaims(ctx, tx, claim.UserID, claim.AudienceID, claim.ClaimedAt)
+		if err != nil {
+			return err
+		}
+		if count >= maxActive {
+			return nil
+		}
+
+		query := squirrel.Insert(claimTable).
+			Columns(append([]string{"id"}, claimsFieldNames...)...).
+			Values(claim.ID, claim.UserID, claim.AudienceID, claim.ClaimedAt, claim.ExpiresAt).
+			Suffix("ON CONFLICT ON CONSTRAINT room_claim_unique DO UPDATE SET claimed_at = EXCLUDED.claimed_at, expires_at = EXCLUDED.expires_at").
+			PlaceholderFormat(squirrel.Dollar)
+
+		sqlText, bound, err := query.ToSql()
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, sqlText, bound...); err != nil {
+			return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, claimTable, markTransient(err))
+		}
+		saved = true
+		return nil
+	})
+	return saved, err
+}
+
+func (d *Database) DeleteClaim(ctx context.Context, userID, audienceID string) error {
+	query := squirrel.Delete(claimTable).
+		Where(squirrel.Eq{"user_id": userID, "audience_id": audienceID}).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot delete from %v: %w", claimTable, markTransient(err))
+	}
+
+	return nil
+}
+
+// DeleteExpiredClaims deletes claims expired by the moment and returns how many there were.
+func (d *Database) DeleteExpiredClaims(ctx context.Context, at time.Time) (int64, error) {
+	query := squirrel.Delete(claimTable).
+		Where(squirrel.LtOrEq{"expires_at": at}).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := d.db.ExecContext(ctx, sql, bound...)
+	if err != nil {
+		return 0, fmt.Errorf("cannot delete from %v: %w", claimTable, markTransient(err))
+	}
+
+	return res.RowsAffected()
+}
+
+// countActiveClaims counts claims of the user active at the moment, except the one on exceptAudienceID.
+func countActiveClaims(ctx context.Context, q sqlx.QueryerContext, userID, exceptAudienceID string, at time.Time) (int, error) {
+	var res int
+
+	query := squirrel.Select("count(*)").
+		From(claimTable).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.NotEq{"audience_id": exceptAudienceID}).
+		Where(squirrel.LtOrEq{"claimed_at": at}).
+		Where(squirrel.Gt{"expires_at": at}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build selection %v SQL: %w", claimTable, err)
+	}
+
+	if err = sqlx.GetContext(ctx, q, &res, sqlText, bound...); err != nil {
+		return 0, mapErrors(err, "cannot select "+claimTable+": %w")
+	}
+
+	return res, nil
+}
+
+type claim struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	AudienceID string    `db:"audience_id"`
+	ClaimedAt  time.Time `db:"claimed_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+func (c claim) toService() service.Claim {
+	return service.Claim{
+		ID:         c.ID,
+		UserID:     c.UserID,
+		AudienceID: c.AudienceID,
+		ClaimedAt:  c.ClaimedAt,
+		ExpiresAt:  c.ExpiresAt,
+	}
+}
+
+func (d *Database) ListActiveClaims(ctx context.Context, userID string, at time.Time) ([]service.Claim, error) {
+	var rows []claim
+
+	query := squirrel.Select(append([]string{"id"}, claimsFieldNames...)...).
+		From(claimTable).
+		Where(squirrel.Eq{"user_id": userID}).
+		Where(squirrel.LtOrEq{"claimed_at": at}).
+		Where(squirrel.Gt{"expires_at": at}).
+		OrderBy("claimed_at").PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build selection %v SQL: %w", claimTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &rows, sqlText, bound...); err != nil {
+		return nil, mapErrors(err, "cannot select "+claimTable+": %w")
+	}
+
+	res := make([]service.Claim, 0, len(rows))
+	for _, c := range rows {
+		res = append(res, c.toService())
+	}
+	return res, nil
+}
@@ -3,9 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/jackc/pgx/v4/stdlib"
 	"github.com/jmoiron/sqlx"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/retry"
 )
 
 type Config struct {
@@ -13,7 +16,7 @@ type Config struct {
 }
 
 type Database struct {
-	db *sqlx.DB
+	db retryingDB
 }
 
 func NewDatabase(ctx context.Context, cfg *Config) (*Database, error) {
@@ -26,7 +29,7 @@ func NewDatabase(ctx context.Context, cfg *Config) (*Database, error) {
 
 	dbx := sqlx.NewDb(sql.OpenDB(ctor), "pgx")
 
-	return &Database{dbx}, nil
+	return &Database{retryingDB{dbx}}, nil
 }
 
 func (d *Database) Ping(ctx context.Context) error {
@@ -36,3 +39,32 @@ func (d *Database) Ping(ctx context.Context) error {
 func (d *Database) Close(ctx context.Context) error {
 	return d.db.Close()
 }
+
+// inTx runs f in a transaction which is committed if f succeeds and rolled back otherwise,
+// the whole transaction is repeated if it fails because of the connection or the server before commit.
+func (d *Database) inTx(ctx context.Context, f func(tx *sqlx.Tx) error) error {
+	// a transaction is replayed as a whole, unless its commit failed: it may have been applied
+	committing := false
+	txRetryable := func(err error) bool {
+		if committing {
+			return safeToRetry(err)
+		}
+		return retryable(err)
+	}
+	return retry.Do(ctx, retryAttempts, retryBackoff, txRetryable, func() error {
+		committing = false
+		tx, err := d.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot begin transaction: %w", markTransient(err))
+		}
+		if err := f(tx); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		committing = true
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("cannot commit transaction: %w", markTransient(err))
+		}
+		return nil
+	})
+}
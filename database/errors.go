@@ -1,9 +1,15 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgconn"
 
 	"github.com/AlexisOMG/bmstu-free-rooms/service"
 )
@@ -13,5 +19,30 @@ func mapErrors(err error, wrap string) error {
 		return fmt.Errorf(wrap, service.ErrorNotFound)
 	}
 
-	return fmt.Errorf(wrap, err)
+	return fmt.Errorf(wrap, markTransient(err))
+}
+
+// markTransient wraps errors worth retrying with service.ErrorTransient.
+func markTransient(err error) error {
+	if isTransient(err) {
+		return fmt.Errorf("%w: %w", service.ErrorTransient, err)
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// connection exceptions, serialization failures and deadlocks, server shutdown
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "40") ||
+			pgErr.Code == "57P01" || pgErr.Code == "57P03"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return pgconn.SafeToRetry(err)
 }
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	favoriteTable       = "favorite_audience"
+	favoritesFieldNames = []string{
+		"user_id",
+		"audience_id",
+	}
+	freeAlertTable       = "free_alert"
+	freeAlertsFieldNames = []string{
+		"user_id",
+		"audience_id",
+	}
+)
+
+func (d *Database) SaveFavorite(ctx context.Context, fav *service.FavoriteAudience) error {
+	query := squirrel.Insert(favoriteTable).
+		Columns(append([]string{"id"}, favoritesFieldNames...)...).
+		Values(fav.ID, fav.UserID, fav.AudienceID).
+		Suffix("ON CONFLICT ON CONSTRAINT favorite_audience_unique DO NOTHING").PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, favoriteTable, markTransient(err))
+	}
+
+	return nil
+}
+
+func (d *Database) DeleteFavorite(ctx context.Context, userID, audienceID string) error {
+	query := squirrel.Delete(favoriteTable).
+		Where(squirrel.Eq{"user_id": userID, "audience_id": audienceID}).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot delete from %v: %w", favoriteTable, markTransient(err))
+	}
+
+	return nil
+}
+
+func (d *Database) ListFavorites(ctx context.Context, userID string) ([]service.Audience, error) {
+	res := []audience{}
+
+	query := squirrel.Select(withPrefix(append([]string{"id"}, audiencesFieldNames...), "a")...).
+		From(favoriteTable+" f").
+		Join(audienceTable+" a ON a.id = f.audience_id").
+		Where(squirrel.Eq{"f.user_id": userID}).
+		OrderBy("a.building", "a.floor", "length(a.number)", "a.number").PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.Audience{}, fmt.Errorf("failed to build selection %v SQL: %w", favoriteTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.Audience{}, mapErrors(err, "cannot select "+favoriteTable+": %w")
+	}
+
+	return audiencesToService(res), nil
+}
+
+func (d *Database) SaveFreeAlert(ctx context.Context, alert *service.FreeAlert) error {
+	query := squirrel.Insert(freeAlertTable).
+		Columns(append([]string{"id"}, freeAlertsFieldNames...)...).
+		Values(alert.ID, alert.UserID, alert.Audience.ID).
+		Suffix("ON CONFLICT ON CONSTRAINT free_alert_unique DO NOTHING").PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, freeAlertTable, markTransient(err))
+	}
+
+	return nil
+}
+
+type freeAlert struct {
+	ID         string `db:"alert_id"`
+	UserID     string `db:"user_id"`
+	TelegramID string `db:"telegram_id"`
+	audience
+}
+
+func (fa *freeAlert) toService() service.FreeAlert {
+	return service.FreeAlert{
+		ID:         fa.ID,
+		UserID:     fa.UserID,
+		TelegramID: fa.TelegramID,
+		Audience:   fa.audience.toService(),
+	}
+}
+
+func (d *Database) ListFreeAlerts(ctx context.Context) ([]service.FreeAlert, error) {
+	res := []freeAlert{}
+
+	query := squirrel.Select(append([]string{"fa.id AS alert_id", "fa.user_id", "u.telegram_id"},
+		withPrefix(append([]string{"id"}, audiencesFieldNames...), "a")...)...).
+		From(freeAlertTable + " fa").
+		Join(userTable + " u ON u.id = fa.user_id").
+		Join(audienceTable + " a ON a.id = fa.audience_id").
+		OrderBy("fa.created_at").PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.FreeAlert{}, fmt.Errorf("failed to build selection %v SQL: %w", freeAlertTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.FreeAlert{}, mapErrors(err, "cannot select "+freeAlertTable+": %w")
+	}
+
+	alerts := make([]service.FreeAlert, 0, len(res))
+	for i := range res {
+		alerts = append(alerts, res[i].toService())
+	}
+	return alerts, nil
+}
+
+func (d *Database) DeleteFreeAlert(ctx context.Context, id string) error {
+	query := squirrel.Delete(freeAlertTable).
+		Where(squirrel.Eq{"id": id}).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot delete from %v: %w", freeAlertTable, markTransient(err))
+	}
+
+	return nil
+}
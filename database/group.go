@@ -78,7 +78,7 @@ func (d *Database) SaveGroups(ctx context.Context, groups ...service.Group) erro
 	}
 
 	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, groupTable, err)
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, groupTable, markTransient(err))
 	}
 
 	return nil
@@ -108,6 +108,24 @@ func (d *Database) ListGroups(ctx context.Context, filters *service.GroupFilters
 	return groupsToService(res), nil
 }
 
+func (d *Database) GetGroup(ctx context.Context, id string) (service.Group, error) {
+	res := group{}
+	query := squirrel.Select(append([]string{"id"}, groupsFieldNames...)...).
+		From(groupTable).
+		Where(squirrel.Eq{"id": id}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return service.Group{}, fmt.Errorf("failed to build selection %v SQL: %w", groupTable, err)
+	}
+
+	if err = d.db.GetContext(ctx, &res, sqlText, bound...); err != nil {
+		return service.Group{}, mapErrors(err, "cannot select "+groupTable+": %w")
+	}
+
+	return res.toService(), nil
+}
+
 type groupLesson struct {
 	ID       string `db:"id"`
 	GroupID  string `db:"group_id"`
@@ -161,7 +179,7 @@ func (d *Database) SaveGroupLessons(ctx context.Context, groupLessons ...service
 	}
 
 	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, groupLessonTable, err)
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, groupLessonTable, markTransient(err))
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
 
@@ -75,12 +76,37 @@ func (d *Database) SaveLessons(ctx context.Context, lessons ...service.Lesson) e
 	}
 
 	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, lessonTable, err)
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, lessonTable, markTransient(err))
 	}
 
 	return nil
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (d *Database) ListTeachers(ctx context.Context, nameParts []string, limit uint64) ([]string, error) {
+	res := []string{}
+	query := squirrel.Select("DISTINCT teacher_name").
+		From(lessonTable).
+		Where(squirrel.NotEq{"teacher_name": nil}).
+		OrderBy("teacher_name").
+		Limit(limit).PlaceholderFormat(squirrel.Dollar)
+	for _, part := range nameParts {
+		query = query.Where(squirrel.ILike{"teacher_name": "%" + likeEscaper.Replace(part) + "%"})
+	}
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to build selection %v SQL: %w", lessonTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []string{}, mapErrors(err, "cannot select "+lessonTable+": %w")
+	}
+
+	return res, nil
+}
+
 func (d *Database) ListLessons(ctx context.Context, filters *service.LessonFilters) ([]service.Lesson, error) {
 	return nil, nil
 }
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var offsetTable = "bot_update_offset"
+
+type updateOffset struct {
+	BotID    int64     `db:"bot_id"`
+	UpdateID int       `db:"update_id"`
+	SavedAt  time.Time `db:"saved_at"`
+}
+
+func (d *Database) GetUpdateOffset(ctx context.Context, botID int64) (service.UpdateOffset, error) {
+	res := updateOffset{}
+	query := squirrel.Select("bot_id", "update_id", "saved_at").
+		From(offsetTable).
+		Where(squirrel.Eq{"bot_id": botID}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return service.UpdateOffset{}, fmt.Errorf("failed to build selection %v SQL: %w", offsetTable, err)
+	}
+
+	if err = d.db.GetContext(ctx, &res, sqlText, bound...); err != nil {
+		return service.UpdateOffset{}, mapErrors(err, "cannot select "+offsetTable+": %w")
+	}
+
+	return service.UpdateOffset{
+		BotID:    res.BotID,
+		UpdateID: res.UpdateID,
+		SavedAt:  res.SavedAt,
+	}, nil
+}
+
+func (d *Database) SaveUpdateOffset(ctx context.Context, offset *service.UpdateOffset) error {
+	query := squirrel.Insert(offsetTable).
+		Columns("bot_id", "update_id", "saved_at").
+		Values(offset.BotID, offset.UpdateID, offset.SavedAt).
+		Suffix(`ON CONFLICT (bot_id) DO UPDATE SET
+			update_id = GREATEST(` + offsetTable + `.update_id, EXCLUDED.update_id),
+			saved_at = EXCLUDED.saved_at`).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, offsetTable, markTransient(err))
+	}
+
+	return nil
+}
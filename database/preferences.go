@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	preferencesTable      = "user_preferences"
+	preferencesFieldNames = []string{
+		"default_building",
+		"default_floor",
+		"group_id",
+		"language",
+		"notifications",
+	}
+)
+
+type preferences struct {
+	UserID          string  `db:"user_id"`
+	DefaultBuilding *string `db:"default_building"`
+	DefaultFloor    *int    `db:"default_floor"`
+	GroupID         *string `db:"group_id"`
+	Language        *string `db:"language"`
+	Notifications   bool    `db:"notifications"`
+}
+
+func (p *preferences) toService() service.Preferences {
+	return service.Preferences{
+		UserID:          p.UserID,
+		DefaultBuilding: p.DefaultBuilding,
+		DefaultFloor:    p.DefaultFloor,
+		GroupID:         p.GroupID,
+		Language:        p.Language,
+		Notifications:   p.Notifications,
+	}
+}
+
+func (p *preferences) values() []interface{} {
+	return []interface{}{
+		p.UserID,
+		p.DefaultBuilding,
+		p.DefaultFloor,
+		p.GroupID,
+		p.Language,
+		p.Notifications,
+	}
+}
+
+func preferencesToDB(p *service.Preferences) preferences {
+	return preferences{
+		UserID:          p.UserID,
+		DefaultBuilding: p.DefaultBuilding,
+		DefaultFloor:    p.DefaultFloor,
+		GroupID:         p.GroupID,
+		Language:        p.Language,
+		Notifications:   p.Notifications,
+	}
+}
+
+func (d *Database) GetPreferences(ctx context.Context, userID string) (service.Preferences, error) {
+	res := preferences{}
+	query := squirrel.Select(append([]string{"user_id"}, preferencesFieldNames...)...).
+		From(preferencesTable).
+		Where(squirrel.Eq{"user_id": userID}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return service.Preferences{}, fmt.Errorf("failed to build selection %v SQL: %w", preferencesTable, err)
+	}
+
+	if err = d.db.GetContext(ctx, &res, sqlText, bound...); err != nil {
+		return service.Preferences{}, mapErrors(err, "cannot select "+preferencesTable+": %w")
+	}
+
+	return res.toService(), nil
+}
+
+func (d *Database) SavePreferences(ctx context.Context, prefs *service.Preferences) error {
+	dbPrefs := preferencesToDB(prefs)
+
+	query := squirrel.
+		Insert(preferencesTable).
+		Columns(append([]string{"user_id"}, preferencesFieldNames...)...).
+		Values(dbPrefs.values()...).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+		default_building = EXCLUDED.default_building,
+		default_floor = EXCLUDED.default_floor,
+		group_id = EXCLUDED.group_id,
+		language = EXCLUDED.language,
+		notifications = EXCLUDED.notifications`).
+		PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, preferencesTable, markTransient(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	reportTable       = "room_report"
+	reportsFieldNames = []string{
+		"user_id",
+		"audience_id",
+		"week_type",
+		"week_day",
+		"period",
+		"state",
+		"reported_at",
+	}
+	reputationTable = "reporter_reputation"
+)
+
+type roomReport struct {
+	ID         string    `db:"id"`
+	UserID     string    `db:"user_id"`
+	AudienceID string    `db:"audience_id"`
+	WeekType   string    `db:"week_type"`
+	WeekDay    string    `db:"week_day"`
+	Period     int       `db:"period"`
+	State      string    `db:"state"`
+	ReportedAt time.Time `db:"reported_at"`
+}
+
+func (r *roomReport) toService() service.RoomReport {
+	return service.RoomReport{
+		ID:         r.ID,
+		UserID:     r.UserID,
+		AudienceID: r.AudienceID,
+		WeekType:   r.WeekType,
+		WeekDay:    r.WeekDay,
+		Period:     r.Period,
+		State:      r.State,
+		ReportedAt: r.ReportedAt,
+	}
+}
+
+func (r *roomReport) values() []interface{} {
+	return []interface{}{
+		r.ID,
+		r.UserID,
+		r.AudienceID,
+		r.WeekType,
+		r.WeekDay,
+		r.Period,
+		r.State,
+		r.ReportedAt,
+	}
+}
+
+func roomReportToDB(r service.RoomReport) roomReport {
+	return roomReport{
+		ID:         r.ID,
+		UserID:     r.UserID,
+		AudienceID: r.AudienceID,
+		WeekType:   r.WeekType,
+		WeekDay:    r.WeekDay,
+		Period:     r.Period,
+		State:      r.State,
+		ReportedAt: r.ReportedAt,
+	}
+}
+
+// SaveReport saves the report and rates the earlier reporters in one transaction, so a failed
+// report leaves no ratings behind.
+func (d *Database) SaveReport(ctx context.Context, report *service.RoomReport, ratings map[string]bool) error {
+	dbReport := roomReportToDB(*report)
+	query := squirrel.Insert(reportTable).
+		Columns(append([]string{"id"}, reportsFieldNames...)...).
+		Values(dbReport.values()...).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	return d.inTx(ctx, func(tx *sqlx.Tx) error {
+		for userID, agreed := range ratings {
+			if err := addReputation(ctx, tx, userID, agreed); err != nil {
+				return err
+			}
+		}
+		if _, err = tx.ExecContext(ctx, sql, bound...); err != nil {
+			return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, reportTable, markTransient(err))
+		}
+		return nil
+	})
+}
+
+func (d *Database) ListReports(ctx context.Context, filters *service.RoomReportFilters) ([]service.RoomReport, error) {
+	res := []roomReport{}
+
+	query := squirrel.Select(append([]string{"id"}, reportsFieldNames...)...).
+		From(reportTable).
+		OrderBy("reported_at").PlaceholderFormat(squirrel.Dollar)
+	if len(filters.AudienceIDs) > 0 {
+		query = query.Where(squirrel.Eq{"audience_id": filters.AudienceIDs})
+	}
+	if filters.WeekType != nil {
+		query = query.Where(squirrel.Eq{"week_type": *filters.WeekType})
+	}
+	if len(filters.WeekDays) > 0 {
+		query = query.Where(squirrel.Eq{"week_day": filters.WeekDays})
+	}
+	if len(filters.Periods) > 0 {
+		query = query.Where(squirrel.Eq{"period": filters.Periods})
+	}
+	if filters.Since != nil {
+		query = query.Where(squirrel.GtOrEq{"reported_at": *filters.Since})
+	}
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.RoomReport{}, fmt.Errorf("failed to build selection %v SQL: %w", reportTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.RoomReport{}, mapErrors(err, "cannot select "+reportTable+": %w")
+	}
+
+	reports := make([]service.RoomReport, 0, len(res))
+	for i := range res {
+		reports = append(reports, res[i].toService())
+	}
+	return reports, nil
+}
+
+type reputation struct {
+	UserID string `db:"user_id"`
+	Agreed int    `db:"agreed"`
+	Total  int    `db:"total"`
+}
+
+func (d *Database) ListReputations(ctx context.Context, userIDs []string) ([]service.Reputation, error) {
+	res := []reputation{}
+	if len(userIDs) == 0 {
+		return []service.Reputation{}, nil
+	}
+
+	query := squirrel.Select("user_id", "agreed", "total").
+		From(reputationTable).
+		Where(squirrel.Eq{"user_id": userIDs}).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.Reputation{}, fmt.Errorf("failed to build selection %v SQL: %w", reputationTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.Reputation{}, mapErrors(err, "cannot select "+reputationTable+": %w")
+	}
+
+	reputations := make([]service.Reputation, 0, len(res))
+	for _, r := range res {
+		reputations = append(reputations, service.Reputation{
+			UserID: r.UserID,
+			Agreed: r.Agreed,
+			Total:  r.Total,
+		})
+	}
+	return reputations, nil
+}
+
+func addReputation(ctx context.Context, e sqlx.ExecerContext, userID string, agreed bool) error {
+	agreedInc := 0
+	if agreed {
+		agreedInc = 1
+	}
+
+	query := squirrel.Insert(reputationTable).
+		Columns("user_id", "agreed", "total").
+		Values(userID, agreedInc, 1).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET agreed = " + reputationTable + ".agreed + EXCLUDED.agreed, total = " + reputationTable + ".total + 1").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = e.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, reputationTable, markTransient(err))
+	}
+
+	return nil
+}
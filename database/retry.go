@@ -0,0 +1,66 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/retry"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	retryAttempts = 3
+	retryBackoff  = 100 * time.Millisecond
+)
+
+// retryable tells whether a failed read, or a transaction which has not reached commit, may succeed
+// if run again. Neither leaves anything behind, so any failure of the connection or the server counts.
+func retryable(err error) bool {
+	return errors.Is(err, service.ErrorTransient) || isTransient(err)
+}
+
+// safeToRetry tells whether a failed write surely was not applied: the statement never reached
+// the server or the server rolled it back. A statement lost with the connection may have run anyway.
+func safeToRetry(err error) bool {
+	return errors.Is(err, driver.ErrBadConn) || pgconn.SafeToRetry(err) || serializationFailure(err)
+}
+
+// serializationFailure tells whether the server rolled the statement back to resolve a conflict.
+func serializationFailure(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
+// retryingDB repeats reads which fail because of the connection or the server, and writes only
+// if they surely were not applied.
+type retryingDB struct {
+	*sqlx.DB
+}
+
+func (db retryingDB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return retry.Do(ctx, retryAttempts, retryBackoff, retryable, func() error {
+		return db.DB.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (db retryingDB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return retry.Do(ctx, retryAttempts, retryBackoff, retryable, func() error {
+		return db.DB.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (db retryingDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := retry.Do(ctx, retryAttempts, retryBackoff, safeToRetry, func() error {
+		var err error
+		res, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return res, err
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestRetryPolicies(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		// read is whether a read or an uncommitted transaction is repeated, write whether a write is
+		read, write bool
+	}{
+		{"connection not established", driver.ErrBadConn, true, true},
+		{"marked transient", fmt.Errorf("cannot select: %w", markTransient(driver.ErrBadConn)), true, true},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true, true},
+		{"deadlock", &pgconn.PgError{Code: "40P01"}, true, true},
+		{"connection lost", &pgconn.PgError{Code: "08006"}, true, false},
+		{"server shutdown", &pgconn.PgError{Code: "57P01"}, true, false},
+		{"network error", &net.OpError{Op: "read", Err: fmt.Errorf("connection reset")}, true, false},
+		{"deadline", context.DeadlineExceeded, true, false},
+		{"no rows", sql.ErrNoRows, false, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryable(tt.err); got != tt.read {
+				t.Errorf("retryable() = %v, want %v", got, tt.read)
+			}
+			if got := safeToRetry(tt.err); got != tt.write {
+				t.Errorf("safeToRetry() = %v, want %v", got, tt.write)
+			}
+		})
+	}
+}
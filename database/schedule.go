@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -96,7 +97,7 @@ func (d *Database) SaveSchedules(ctx context.Context, schedules ...service.Sched
 	}
 
 	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", sql, bound, scheduleTable, err)
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", sql, bound, scheduleTable, markTransient(err))
 	}
 
 	return nil
@@ -105,3 +106,117 @@ func (d *Database) SaveSchedules(ctx context.Context, schedules ...service.Sched
 func (d *Database) ListSchedules(ctx context.Context, filters *service.ScheduleFilters) ([]service.Schedule, error) {
 	return nil, nil
 }
+
+type scheduleEntry struct {
+	ScheduleID  string     `db:"schedule_id"`
+	WeekType    string     `db:"week_type"`
+	WeekDay     string     `db:"week_day"`
+	Period      int        `db:"period"`
+	Start       *time.Time `db:"lesson_start"`
+	End         *time.Time `db:"lesson_end"`
+	LessonID    string     `db:"lesson_id"`
+	LessonName  string     `db:"lesson_name"`
+	TeacherName *string    `db:"teacher_name"`
+	Kind        *string    `db:"kind"`
+	AudienceID  string     `db:"audience_id"`
+	Number      string     `db:"number"`
+	Building    string     `db:"building"`
+	Floor       int        `db:"floor"`
+	Suffix      *string    `db:"suffix"`
+	Groups      string     `db:"groups"`
+}
+
+func (e *scheduleEntry) toService() service.ScheduleEntry {
+	res := service.ScheduleEntry{
+		ScheduleID: e.ScheduleID,
+		WeekType:   e.WeekType,
+		WeekDay:    e.WeekDay,
+		Period:     e.Period,
+		Start:      e.Start,
+		End:        e.End,
+		Lesson: service.Lesson{
+			ID:          e.LessonID,
+			Name:        e.LessonName,
+			TeacherName: e.TeacherName,
+			Kind:        e.Kind,
+		},
+		Audience: service.Audience{
+			ID:       e.AudienceID,
+			Number:   e.Number,
+			Building: e.Building,
+			Floor:    e.Floor,
+			Suffix:   e.Suffix,
+		},
+		Groups: []string{},
+	}
+	if e.Groups != "" {
+		res.Groups = strings.Split(e.Groups, ",")
+	}
+	return res
+}
+
+func scheduleEntriesToService(entries []scheduleEntry) []service.ScheduleEntry {
+	res := make([]service.ScheduleEntry, 0, len(entries))
+	for i := range entries {
+		res = append(res, entries[i].toService())
+	}
+	return res
+}
+
+func (d *Database) ListScheduleEntries(ctx context.Context, filters *service.ScheduleEntryFilters) ([]service.ScheduleEntry, error) {
+	res := []scheduleEntry{}
+
+	query := squirrel.Select(
+		"s.id AS schedule_id",
+		"s.week_type",
+		"s.week_day",
+		"s.period",
+		"s.lesson_start",
+		"s.lesson_end",
+		"l.id AS lesson_id",
+		"l.name AS lesson_name",
+		"l.teacher_name",
+		"l.kind",
+		"a.id AS audience_id",
+		"a.number",
+		"a.building",
+		"a.floor",
+		"a.suffix",
+		"COALESCE(string_agg(DISTINCT g.name, ','), '') AS groups",
+	).
+		From(scheduleTable+" s").
+		Join(lessonTable+" l ON l.id = s.lesson_id").
+		Join(audienceTable+" a ON a.id = s.audience_id").
+		LeftJoin(groupLessonTable+" gl ON gl.lesson_id = l.id").
+		LeftJoin(groupTable+" g ON g.id = gl.group_id").
+		GroupBy("s.id", "l.id", "a.id").
+		OrderBy("s.period").
+		PlaceholderFormat(squirrel.Dollar)
+
+	if len(filters.AudienceIDs) > 0 {
+		query = query.Where(squirrel.Eq{"s.audience_id": filters.AudienceIDs})
+	}
+	if filters.GroupID != nil {
+		query = query.Where("EXISTS (SELECT 1 FROM "+groupLessonTable+" f WHERE f.lesson_id = s.lesson_id AND f.group_id = ?)", *filters.GroupID)
+	}
+	if filters.TeacherName != nil {
+		query = query.Where(squirrel.Eq{"l.teacher_name": filters.TeacherName})
+	}
+	if filters.WeekType != nil {
+		query = query.Where(squirrel.Eq{"s.week_type": filters.WeekType})
+	}
+	if filters.WeekDay != nil {
+		query = query.Where(squirrel.Eq{"s.week_day": filters.WeekDay})
+	}
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.ScheduleEntry{}, fmt.Errorf("failed to build selection %v SQL: %w", scheduleTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.ScheduleEntry{}, mapErrors(err, "cannot select "+scheduleTable+": %w")
+	}
+
+	return scheduleEntriesToService(res), nil
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	queryStatsTable   = "query_stats"
+	importTable       = "schedule_import"
+	importsFieldNames = []string{
+		"started_at",
+		"finished_at",
+		"files",
+		"imported",
+		"quarantined_files",
+		"quarantined_events",
+		"error",
+	}
+)
+
+type queryCount struct {
+	Day     time.Time `db:"day"`
+	Command string    `db:"command"`
+	Count   int       `db:"count"`
+}
+
+func (d *Database) AddQueryCount(ctx context.Context, day time.Time, command string) error {
+	query := squirrel.Insert(queryStatsTable).
+		Columns("day", "command", "count").
+		Values(day, command, 1).
+		Suffix("ON CONFLICT (day, command) DO UPDATE SET count = " + queryStatsTable + ".count + 1").
+		PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, queryStatsTable, markTransient(err))
+	}
+
+	return nil
+}
+
+func (d *Database) ListQueryCounts(ctx context.Context, since time.Time) ([]service.QueryCount, error) {
+	res := []queryCount{}
+
+	query := squirrel.Select("day", "command", "count").
+		From(queryStatsTable).
+		Where(squirrel.GtOrEq{"day": since}).
+		OrderBy("day", "command").PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return []service.QueryCount{}, fmt.Errorf("failed to build selection %v SQL: %w", queryStatsTable, err)
+	}
+
+	if err = d.db.SelectContext(ctx, &res, sqlText, bound...); err != nil {
+		return []service.QueryCount{}, mapErrors(err, "cannot select "+queryStatsTable+": %w")
+	}
+
+	counts := make([]service.QueryCount, 0, len(res))
+	for _, c := range res {
+		counts = append(counts, service.QueryCount{
+			Day:     c.Day,
+			Command: c.Command,
+			Count:   c.Count,
+		})
+	}
+	return counts, nil
+}
+
+type scheduleImport struct {
+	ID                string    `db:"id"`
+	StartedAt         time.Time `db:"started_at"`
+	FinishedAt        time.Time `db:"finished_at"`
+	Files             int       `db:"files"`
+	Imported          int       `db:"imported"`
+	QuarantinedFiles  int       `db:"quarantined_files"`
+	QuarantinedEvents int       `db:"quarantined_events"`
+	Error             *string   `db:"error"`
+}
+
+func (i *scheduleImport) toService() service.ScheduleImport {
+	return service.ScheduleImport{
+		ID:                i.ID,
+		StartedAt:         i.StartedAt,
+		FinishedAt:        i.FinishedAt,
+		Files:             i.Files,
+		Imported:          i.Imported,
+		QuarantinedFiles:  i.QuarantinedFiles,
+		QuarantinedEvents: i.QuarantinedEvents,
+		Error:             i.Error,
+	}
+}
+
+func (i *scheduleImport) values() []interface{} {
+	return []interface{}{
+		i.ID,
+		i.StartedAt,
+		i.FinishedAt,
+		i.Files,
+		i.Imported,
+		i.QuarantinedFiles,
+		i.QuarantinedEvents,
+		i.Error,
+	}
+}
+
+func scheduleImportToDB(i *service.ScheduleImport) scheduleImport {
+	return scheduleImport{
+		ID:                i.ID,
+		StartedAt:         i.StartedAt,
+		FinishedAt:        i.FinishedAt,
+		Files:             i.Files,
+		Imported:          i.Imported,
+		QuarantinedFiles:  i.QuarantinedFiles,
+		QuarantinedEvents: i.QuarantinedEvents,
+		Error:             i.Error,
+	}
+}
+
+func (d *Database) SaveImport(ctx context.Context, imp *service.ScheduleImport) error {
+	dbImport := scheduleImportToDB(imp)
+	query := squirrel.Insert(importTable).
+		Columns(append([]string{"id"}, importsFieldNames...)...).
+		Values(dbImport.values()...).PlaceholderFormat(squirrel.Dollar)
+
+	sql, bound, err := query.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, importTable, markTransient(err))
+	}
+
+	return nil
+}
+
+func (d *Database) GetLastImport(ctx context.Context) (service.ScheduleImport, error) {
+	res := scheduleImport{}
+
+	query := squirrel.Select(append([]string{"id"}, importsFieldNames...)...).
+		From(importTable).
+		OrderBy("started_at DESC").
+		Limit(1).PlaceholderFormat(squirrel.Dollar)
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return service.ScheduleImport{}, fmt.Errorf("failed to build selection %v SQL: %w", importTable, err)
+	}
+
+	if err = d.db.GetContext(ctx, &res, sqlText, bound...); err != nil {
+		return service.ScheduleImport{}, mapErrors(err, "cannot select "+importTable+": %w")
+	}
+
+	return res.toService(), nil
+}
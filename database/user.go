@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/squirrel"
 
@@ -14,6 +15,8 @@ var (
 	usersFieldNames = []string{
 		"telegram_id",
 		"username",
+		"firstname",
+		"lastname",
 		"phone",
 	}
 )
@@ -22,7 +25,10 @@ type user struct {
 	ID         string  `db:"id"`
 	TelegramID string  `db:"telegram_id"`
 	Username   *string `db:"username"`
+	FirstName  *string `db:"firstname"`
+	LastName   *string `db:"lastname"`
 	Phone      *string `db:"phone"`
+	IsAdmin    bool    `db:"is_admin"`
 }
 
 func (u *user) toService() service.User {
@@ -30,7 +36,10 @@ func (u *user) toService() service.User {
 		ID:         u.ID,
 		TelegramID: u.TelegramID,
 		Username:   u.Username,
+		FirstName:  u.FirstName,
+		LastName:   u.LastName,
 		Phone:      u.Phone,
+		IsAdmin:    u.IsAdmin,
 	}
 }
 
@@ -39,6 +48,8 @@ func (u *user) values() []interface{} {
 		u.ID,
 		u.TelegramID,
 		u.Username,
+		u.FirstName,
+		u.LastName,
 		u.Phone,
 	}
 }
@@ -48,6 +59,8 @@ func userToDB(u *service.User) user {
 		ID:         u.ID,
 		TelegramID: u.TelegramID,
 		Username:   u.Username,
+		FirstName:  u.FirstName,
+		LastName:   u.LastName,
 		Phone:      u.Phone,
 	}
 }
@@ -68,15 +81,21 @@ func (d *Database) SaveUser(ctx context.Context, user *service.User) error {
 		Columns(append([]string{"id"}, usersFieldNames...)...).
 		Values(dbUser.values()...)
 
-	query = query.Suffix("ON CONFLICT ON CONSTRAINT telegram_unique DO NOTHING").PlaceholderFormat(squirrel.Dollar)
+	// returning id of an existing row requires DO UPDATE, DO NOTHING returns no rows
+	query = query.Suffix(`ON CONFLICT ON CONSTRAINT telegram_unique DO UPDATE SET
+		username = EXCLUDED.username,
+		firstname = EXCLUDED.firstname,
+		lastname = EXCLUDED.lastname,
+		phone = COALESCE(EXCLUDED.phone, ` + userTable + `.phone)
+		RETURNING id`).PlaceholderFormat(squirrel.Dollar)
 
 	sql, bound, err := query.ToSql()
 	if err != nil {
 		return err
 	}
 
-	if _, err = d.db.ExecContext(ctx, sql, bound...); err != nil {
-		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, userTable, err)
+	if err = d.db.GetContext(ctx, &user.ID, sql, bound...); err != nil {
+		return fmt.Errorf("cannot insert query: %v, args %v, into %v: %w", query, bound, userTable, markTransient(err))
 	}
 
 	return nil
@@ -85,7 +104,7 @@ func (d *Database) SaveUser(ctx context.Context, user *service.User) error {
 func (d *Database) ListUsers(ctx context.Context, filters *service.UserFilters) ([]service.User, error) {
 	res := []user{}
 
-	query := squirrel.Select(append([]string{"id"}, usersFieldNames...)...).
+	query := squirrel.Select(append(append([]string{"id"}, usersFieldNames...), "is_admin")...).
 		From(userTable).PlaceholderFormat(squirrel.Dollar)
 
 	if len(filters.TelegramIDs) > 0 {
@@ -103,3 +122,23 @@ func (d *Database) ListUsers(ctx context.Context, filters *service.UserFilters)
 
 	return usersToService(res), nil
 }
+
+func (d *Database) CountUsers(ctx context.Context, createdSince *time.Time) (int, error) {
+	var count int
+
+	query := squirrel.Select("count(*)").From(userTable).PlaceholderFormat(squirrel.Dollar)
+	if createdSince != nil {
+		query = query.Where(squirrel.GtOrEq{"created_at": *createdSince})
+	}
+
+	sqlText, bound, err := query.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	if err = d.db.GetContext(ctx, &count, sqlText, bound...); err != nil {
+		return 0, mapErrors(err, "cannot count "+userTable+": %w")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,1487 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: freerooms.proto
+
+package freeroomspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type WeekType int32
+
+const (
+	WeekType_WEEK_TYPE_UNSPECIFIED WeekType = 0
+	// ЧС, odd weeks of the semester
+	WeekType_WEEK_TYPE_NUMERATOR WeekType = 1
+	// ЗН, even weeks of the semester
+	WeekType_WEEK_TYPE_DENOMINATOR WeekType = 2
+)
+
+// Enum value maps for WeekType.
+var (
+	WeekType_name = map[int32]string{
+		0: "WEEK_TYPE_UNSPECIFIED",
+		1: "WEEK_TYPE_NUMERATOR",
+		2: "WEEK_TYPE_DENOMINATOR",
+	}
+	WeekType_value = map[string]int32{
+		"WEEK_TYPE_UNSPECIFIED": 0,
+		"WEEK_TYPE_NUMERATOR":   1,
+		"WEEK_TYPE_DENOMINATOR": 2,
+	}
+)
+
+func (x WeekType) Enum() *WeekType {
+	p := new(WeekType)
+	*p = x
+	return p
+}
+
+func (x WeekType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (WeekType) Descriptor() protoreflect.EnumDescriptor {
+	return file_freerooms_proto_enumTypes[0].Descriptor()
+}
+
+func (WeekType) Type() protoreflect.EnumType {
+	return &file_freerooms_proto_enumTypes[0]
+}
+
+func (x WeekType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use WeekType.Descriptor instead.
+func (WeekType) EnumDescriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{0}
+}
+
+type Audience struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id       string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Number   string  `protobuf:"bytes,2,opt,name=number,proto3" json:"number,omitempty"`
+	Building string  `protobuf:"bytes,3,opt,name=building,proto3" json:"building,omitempty"`
+	Floor    int32   `protobuf:"varint,4,opt,name=floor,proto3" json:"floor,omitempty"`
+	Suffix   *string `protobuf:"bytes,5,opt,name=suffix,proto3,oneof" json:"suffix,omitempty"`
+	// capacity, kind and equipment are unknown for most audiences
+	Capacity *int32 `protobuf:"varint,6,opt,name=capacity,proto3,oneof" json:"capacity,omitempty"`
+	// lecture, computer, lab or seminar
+	Kind *string `protobuf:"bytes,7,opt,name=kind,proto3,oneof" json:"kind,omitempty"`
+	// projector, whiteboard, computers or sockets
+	Equipment []string `protobuf:"bytes,8,rep,name=equipment,proto3" json:"equipment,omitempty"`
+}
+
+func (x *Audience) Reset() {
+	*x = Audience{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Audience) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Audience) ProtoMessage() {}
+
+func (x *Audience) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Audience.ProtoReflect.Descriptor instead.
+func (*Audience) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Audience) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Audience) GetNumber() string {
+	if x != nil {
+		return x.Number
+	}
+	return ""
+}
+
+func (x *Audience) GetBuilding() string {
+	if x != nil {
+		return x.Building
+	}
+	return ""
+}
+
+func (x *Audience) GetFloor() int32 {
+	if x != nil {
+		return x.Floor
+	}
+	return 0
+}
+
+func (x *Audience) GetSuffix() string {
+	if x != nil && x.Suffix != nil {
+		return *x.Suffix
+	}
+	return ""
+}
+
+func (x *Audience) GetCapacity() int32 {
+	if x != nil && x.Capacity != nil {
+		return *x.Capacity
+	}
+	return 0
+}
+
+func (x *Audience) GetKind() string {
+	if x != nil && x.Kind != nil {
+		return *x.Kind
+	}
+	return ""
+}
+
+func (x *Audience) GetEquipment() []string {
+	if x != nil {
+		return x.Equipment
+	}
+	return nil
+}
+
+// ListEmptyAudiencesRequest mirrors the filter of the service. Empty buildings or floors mean any
+// building or floor, like the REST API the week days and periods default to the current or the next period.
+type ListEmptyAudiencesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Buildings []string `protobuf:"bytes,1,rep,name=buildings,proto3" json:"buildings,omitempty"`
+	Floors    []int32  `protobuf:"varint,2,rep,packed,name=floors,proto3" json:"floors,omitempty"`
+	// the current week if unspecified
+	WeekType WeekType `protobuf:"varint,3,opt,name=week_type,json=weekType,proto3,enum=freerooms.v1.WeekType" json:"week_type,omitempty"`
+	// english names, e.g. Monday; the day of the current or the next period if empty
+	WeekDays []string `protobuf:"bytes,4,rep,name=week_days,json=weekDays,proto3" json:"week_days,omitempty"`
+	// periods the audience must be free for, 1 to 7; the current or the next period on its day if empty
+	Periods []int32 `protobuf:"varint,5,rep,packed,name=periods,proto3" json:"periods,omitempty"`
+	// claims active at the moment count, claims are ignored if unset
+	ClaimsAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=claims_at,json=claimsAt,proto3" json:"claims_at,omitempty"`
+	// drop claimed audiences instead of marking them
+	HideClaimed bool `protobuf:"varint,7,opt,name=hide_claimed,json=hideClaimed,proto3" json:"hide_claimed,omitempty"`
+	// audiences of unknown capacity are dropped if set
+	MinCapacity int32 `protobuf:"varint,8,opt,name=min_capacity,json=minCapacity,proto3" json:"min_capacity,omitempty"`
+	// keep audiences of any of the kinds
+	Kinds []string `protobuf:"bytes,9,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	// keep audiences having all of the equipment
+	Equipment []string `protobuf:"bytes,10,rep,name=equipment,proto3" json:"equipment,omitempty"`
+}
+
+func (x *ListEmptyAudiencesRequest) Reset() {
+	*x = ListEmptyAudiencesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListEmptyAudiencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEmptyAudiencesRequest) ProtoMessage() {}
+
+func (x *ListEmptyAudiencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEmptyAudiencesRequest.ProtoReflect.Descriptor instead.
+func (*ListEmptyAudiencesRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListEmptyAudiencesRequest) GetBuildings() []string {
+	if x != nil {
+		return x.Buildings
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetFloors() []int32 {
+	if x != nil {
+		return x.Floors
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetWeekType() WeekType {
+	if x != nil {
+		return x.WeekType
+	}
+	return WeekType_WEEK_TYPE_UNSPECIFIED
+}
+
+func (x *ListEmptyAudiencesRequest) GetWeekDays() []string {
+	if x != nil {
+		return x.WeekDays
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetPeriods() []int32 {
+	if x != nil {
+		return x.Periods
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetClaimsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClaimsAt
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetHideClaimed() bool {
+	if x != nil {
+		return x.HideClaimed
+	}
+	return false
+}
+
+func (x *ListEmptyAudiencesRequest) GetMinCapacity() int32 {
+	if x != nil {
+		return x.MinCapacity
+	}
+	return 0
+}
+
+func (x *ListEmptyAudiencesRequest) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+func (x *ListEmptyAudiencesRequest) GetEquipment() []string {
+	if x != nil {
+		return x.Equipment
+	}
+	return nil
+}
+
+type EmptyAudience struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Audience *Audience `protobuf:"bytes,1,opt,name=audience,proto3" json:"audience,omitempty"`
+	// the first occupied period after the requested ones, 0 if it stays free till the end of the day
+	NextBusyPeriod int32 `protobuf:"varint,2,opt,name=next_busy_period,json=nextBusyPeriod,proto3" json:"next_busy_period,omitempty"`
+	// set if some user has claimed the audience
+	ClaimedUntil *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=claimed_until,json=claimedUntil,proto3" json:"claimed_until,omitempty"`
+	// how likely the audience is occupied or locked anyway, estimated from user reports
+	UnavailableConfidence float64 `protobuf:"fixed64,4,opt,name=unavailable_confidence,json=unavailableConfidence,proto3" json:"unavailable_confidence,omitempty"`
+	// users reported the audience unavailable often enough to doubt the timetable
+	Suspicious bool `protobuf:"varint,5,opt,name=suspicious,proto3" json:"suspicious,omitempty"`
+}
+
+func (x *EmptyAudience) Reset() {
+	*x = EmptyAudience{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EmptyAudience) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmptyAudience) ProtoMessage() {}
+
+func (x *EmptyAudience) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmptyAudience.ProtoReflect.Descriptor instead.
+func (*EmptyAudience) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EmptyAudience) GetAudience() *Audience {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+func (x *EmptyAudience) GetNextBusyPeriod() int32 {
+	if x != nil {
+		return x.NextBusyPeriod
+	}
+	return 0
+}
+
+func (x *EmptyAudience) GetClaimedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ClaimedUntil
+	}
+	return nil
+}
+
+func (x *EmptyAudience) GetUnavailableConfidence() float64 {
+	if x != nil {
+		return x.UnavailableConfidence
+	}
+	return 0
+}
+
+func (x *EmptyAudience) GetSuspicious() bool {
+	if x != nil {
+		return x.Suspicious
+	}
+	return false
+}
+
+type Lesson struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id          string  `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string  `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	TeacherName *string `protobuf:"bytes,3,opt,name=teacher_name,json=teacherName,proto3,oneof" json:"teacher_name,omitempty"`
+	Kind        *string `protobuf:"bytes,4,opt,name=kind,proto3,oneof" json:"kind,omitempty"`
+}
+
+func (x *Lesson) Reset() {
+	*x = Lesson{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Lesson) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lesson) ProtoMessage() {}
+
+func (x *Lesson) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lesson.ProtoReflect.Descriptor instead.
+func (*Lesson) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Lesson) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Lesson) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Lesson) GetTeacherName() string {
+	if x != nil && x.TeacherName != nil {
+		return *x.TeacherName
+	}
+	return ""
+}
+
+func (x *Lesson) GetKind() string {
+	if x != nil && x.Kind != nil {
+		return *x.Kind
+	}
+	return ""
+}
+
+type ScheduleEntry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ScheduleId string   `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	WeekType   WeekType `protobuf:"varint,2,opt,name=week_type,json=weekType,proto3,enum=freerooms.v1.WeekType" json:"week_type,omitempty"`
+	WeekDay    string   `protobuf:"bytes,3,opt,name=week_day,json=weekDay,proto3" json:"week_day,omitempty"`
+	Period     int32    `protobuf:"varint,4,opt,name=period,proto3" json:"period,omitempty"`
+	// start and end of the period in Moscow time, e.g. 08:30
+	Start    string    `protobuf:"bytes,5,opt,name=start,proto3" json:"start,omitempty"`
+	End      string    `protobuf:"bytes,6,opt,name=end,proto3" json:"end,omitempty"`
+	Lesson   *Lesson   `protobuf:"bytes,7,opt,name=lesson,proto3" json:"lesson,omitempty"`
+	Audience *Audience `protobuf:"bytes,8,opt,name=audience,proto3" json:"audience,omitempty"`
+	Groups   []string  `protobuf:"bytes,9,rep,name=groups,proto3" json:"groups,omitempty"`
+}
+
+func (x *ScheduleEntry) Reset() {
+	*x = ScheduleEntry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleEntry) ProtoMessage() {}
+
+func (x *ScheduleEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleEntry.ProtoReflect.Descriptor instead.
+func (*ScheduleEntry) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScheduleEntry) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *ScheduleEntry) GetWeekType() WeekType {
+	if x != nil {
+		return x.WeekType
+	}
+	return WeekType_WEEK_TYPE_UNSPECIFIED
+}
+
+func (x *ScheduleEntry) GetWeekDay() string {
+	if x != nil {
+		return x.WeekDay
+	}
+	return ""
+}
+
+func (x *ScheduleEntry) GetPeriod() int32 {
+	if x != nil {
+		return x.Period
+	}
+	return 0
+}
+
+func (x *ScheduleEntry) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *ScheduleEntry) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *ScheduleEntry) GetLesson() *Lesson {
+	if x != nil {
+		return x.Lesson
+	}
+	return nil
+}
+
+func (x *ScheduleEntry) GetAudience() *Audience {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+func (x *ScheduleEntry) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// ScheduleFilter narrows a timetable, unset fields mean any.
+type ScheduleFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	WeekType WeekType `protobuf:"varint,1,opt,name=week_type,json=weekType,proto3,enum=freerooms.v1.WeekType" json:"week_type,omitempty"`
+	WeekDay  *string  `protobuf:"bytes,2,opt,name=week_day,json=weekDay,proto3,oneof" json:"week_day,omitempty"`
+}
+
+func (x *ScheduleFilter) Reset() {
+	*x = ScheduleFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleFilter) ProtoMessage() {}
+
+func (x *ScheduleFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleFilter.ProtoReflect.Descriptor instead.
+func (*ScheduleFilter) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ScheduleFilter) GetWeekType() WeekType {
+	if x != nil {
+		return x.WeekType
+	}
+	return WeekType_WEEK_TYPE_UNSPECIFIED
+}
+
+func (x *ScheduleFilter) GetWeekDay() string {
+	if x != nil && x.WeekDay != nil {
+		return *x.WeekDay
+	}
+	return ""
+}
+
+type ListAudienceScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AudienceId string          `protobuf:"bytes,1,opt,name=audience_id,json=audienceId,proto3" json:"audience_id,omitempty"`
+	Filter     *ScheduleFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *ListAudienceScheduleRequest) Reset() {
+	*x = ListAudienceScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListAudienceScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAudienceScheduleRequest) ProtoMessage() {}
+
+func (x *ListAudienceScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAudienceScheduleRequest.ProtoReflect.Descriptor instead.
+func (*ListAudienceScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListAudienceScheduleRequest) GetAudienceId() string {
+	if x != nil {
+		return x.AudienceId
+	}
+	return ""
+}
+
+func (x *ListAudienceScheduleRequest) GetFilter() *ScheduleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type Group struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *Group) Reset() {
+	*x = Group{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Group) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Group) ProtoMessage() {}
+
+func (x *Group) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Group.ProtoReflect.Descriptor instead.
+func (*Group) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Group) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Group) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type SearchGroupsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *SearchGroupsRequest) Reset() {
+	*x = SearchGroupsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchGroupsRequest) ProtoMessage() {}
+
+func (x *SearchGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchGroupsRequest.ProtoReflect.Descriptor instead.
+func (*SearchGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchGroupsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type SearchGroupsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// an exact match is returned alone
+	Groups []*Group `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+}
+
+func (x *SearchGroupsResponse) Reset() {
+	*x = SearchGroupsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchGroupsResponse) ProtoMessage() {}
+
+func (x *SearchGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchGroupsResponse.ProtoReflect.Descriptor instead.
+func (*SearchGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchGroupsResponse) GetGroups() []*Group {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+type ListGroupScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GroupId string          `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Filter  *ScheduleFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *ListGroupScheduleRequest) Reset() {
+	*x = ListGroupScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListGroupScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGroupScheduleRequest) ProtoMessage() {}
+
+func (x *ListGroupScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGroupScheduleRequest.ProtoReflect.Descriptor instead.
+func (*ListGroupScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListGroupScheduleRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ListGroupScheduleRequest) GetFilter() *ScheduleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+type SearchTeachersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *SearchTeachersRequest) Reset() {
+	*x = SearchTeachersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchTeachersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTeachersRequest) ProtoMessage() {}
+
+func (x *SearchTeachersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTeachersRequest.ProtoReflect.Descriptor instead.
+func (*SearchTeachersRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SearchTeachersRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type SearchTeachersResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// at most 10 names, an exact match is returned alone
+	Names []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+}
+
+func (x *SearchTeachersResponse) Reset() {
+	*x = SearchTeachersResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SearchTeachersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTeachersResponse) ProtoMessage() {}
+
+func (x *SearchTeachersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTeachersResponse.ProtoReflect.Descriptor instead.
+func (*SearchTeachersResponse) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SearchTeachersResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+type ListTeacherScheduleRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TeacherName string          `protobuf:"bytes,1,opt,name=teacher_name,json=teacherName,proto3" json:"teacher_name,omitempty"`
+	Filter      *ScheduleFilter `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *ListTeacherScheduleRequest) Reset() {
+	*x = ListTeacherScheduleRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_freerooms_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListTeacherScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTeacherScheduleRequest) ProtoMessage() {}
+
+func (x *ListTeacherScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_freerooms_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTeacherScheduleRequest.ProtoReflect.Descriptor instead.
+func (*ListTeacherScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_freerooms_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTeacherScheduleRequest) GetTeacherName() string {
+	if x != nil {
+		return x.TeacherName
+	}
+	return ""
+}
+
+func (x *ListTeacherScheduleRequest) GetFilter() *ScheduleFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+var File_freerooms_proto protoreflect.FileDescriptor
+
+var file_freerooms_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0c, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x1a,
+	0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0xfa, 0x01, 0x0a, 0x08, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a,
+	0x06, 0x6e, 0x75, 0x6d, 0x62, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6e,
+	0x75, 0x6d, 0x62, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x69, 0x6e,
+	0x67, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x62, 0x75, 0x69, 0x6c, 0x64, 0x69, 0x6e,
+	0x67, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x6c, 0x6f, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x05, 0x66, 0x6c, 0x6f, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x06, 0x73, 0x75, 0x66, 0x66, 0x69,
+	0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06, 0x73, 0x75, 0x66, 0x66, 0x69,
+	0x78, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x48, 0x01, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69,
+	0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x88, 0x01, 0x01, 0x12, 0x1c,
+	0x0a, 0x09, 0x65, 0x71, 0x75, 0x69, 0x70, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x09, 0x65, 0x71, 0x75, 0x69, 0x70, 0x6d, 0x65, 0x6e, 0x74, 0x42, 0x09, 0x0a, 0x07,
+	0x5f, 0x73, 0x75, 0x66, 0x66, 0x69, 0x78, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x63, 0x61, 0x70, 0x61,
+	0x63, 0x69, 0x74, 0x79, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6b, 0x69, 0x6e, 0x64, 0x22, 0xf0, 0x02,
+	0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x41, 0x75, 0x64, 0x69, 0x65,
+	0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62,
+	0x75, 0x69, 0x6c, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09,
+	0x62, 0x75, 0x69, 0x6c, 0x64, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6c, 0x6f,
+	0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x06, 0x66, 0x6c, 0x6f, 0x6f, 0x72,
+	0x73, 0x12, 0x33, 0x0a, 0x09, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x65, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x77, 0x65,
+	0x65, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x64,
+	0x61, 0x79, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x44,
+	0x61, 0x79, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x05, 0x52, 0x07, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x73, 0x12, 0x37, 0x0a,
+	0x09, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x08, 0x63, 0x6c,
+	0x61, 0x69, 0x6d, 0x73, 0x41, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x69, 0x64, 0x65, 0x5f, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x68, 0x69,
+	0x64, 0x65, 0x43, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e,
+	0x5f, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0b, 0x6d, 0x69, 0x6e, 0x43, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x6b, 0x69, 0x6e, 0x64, 0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6b, 0x69, 0x6e,
+	0x64, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x71, 0x75, 0x69, 0x70, 0x6d, 0x65, 0x6e, 0x74, 0x18,
+	0x0a, 0x20, 0x03, 0x28, 0x09, 0x52, 0x09, 0x65, 0x71, 0x75, 0x69, 0x70, 0x6d, 0x65, 0x6e, 0x74,
+	0x22, 0x85, 0x02, 0x0a, 0x0d, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e,
+	0x63, 0x65, 0x12, 0x32, 0x0a, 0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x61, 0x75,
+	0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x62,
+	0x75, 0x73, 0x79, 0x5f, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x0e, 0x6e, 0x65, 0x78, 0x74, 0x42, 0x75, 0x73, 0x79, 0x50, 0x65, 0x72, 0x69, 0x6f, 0x64,
+	0x12, 0x3f, 0x0a, 0x0d, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x5f, 0x75, 0x6e, 0x74, 0x69,
+	0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x52, 0x0c, 0x63, 0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x55, 0x6e, 0x74, 0x69,
+	0x6c, 0x12, 0x35, 0x0a, 0x16, 0x75, 0x6e, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65,
+	0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x15, 0x75, 0x6e, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c, 0x65, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x75, 0x73, 0x70,
+	0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x73, 0x75,
+	0x73, 0x70, 0x69, 0x63, 0x69, 0x6f, 0x75, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x06, 0x4c, 0x65, 0x73,
+	0x73, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x26, 0x0a, 0x0c, 0x74, 0x65, 0x61, 0x63, 0x68,
+	0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x0b, 0x74, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12,
+	0x17, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52,
+	0x04, 0x6b, 0x69, 0x6e, 0x64, 0x88, 0x01, 0x01, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x74, 0x65, 0x61,
+	0x63, 0x68, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x6b, 0x69,
+	0x6e, 0x64, 0x22, 0xba, 0x02, 0x0a, 0x0d, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x63, 0x68, 0x65, 0x64,
+	0x75, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x33, 0x0a, 0x09, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72,
+	0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x65, 0x6b, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x77, 0x65,
+	0x65, 0x6b, 0x5f, 0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x77, 0x65,
+	0x65, 0x6b, 0x44, 0x61, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x65, 0x72, 0x69, 0x6f, 0x64, 0x12, 0x14, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x2c, 0x0a, 0x06, 0x6c, 0x65, 0x73, 0x73, 0x6f, 0x6e, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x73, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x6c, 0x65, 0x73,
+	0x73, 0x6f, 0x6e, 0x12, 0x32, 0x0a, 0x08, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x52, 0x08, 0x61,
+	0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x18, 0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22,
+	0x72, 0x0a, 0x0e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x12, 0x33, 0x0a, 0x09, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x57, 0x65, 0x65, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x77, 0x65,
+	0x65, 0x6b, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1e, 0x0a, 0x08, 0x77, 0x65, 0x65, 0x6b, 0x5f, 0x64,
+	0x61, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x07, 0x77, 0x65, 0x65, 0x6b,
+	0x44, 0x61, 0x79, 0x88, 0x01, 0x01, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x77, 0x65, 0x65, 0x6b, 0x5f,
+	0x64, 0x61, 0x79, 0x22, 0x74, 0x0a, 0x1b, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x75, 0x64, 0x69, 0x65,
+	0x6e, 0x63, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x5f, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63,
+	0x65, 0x49, 0x64, 0x12, 0x34, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65,
+	0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x2b, 0x0a, 0x05, 0x47, 0x72, 0x6f,
+	0x75, 0x70, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x29, 0x0a, 0x13, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x22, 0x43, 0x0a, 0x14, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x06, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x66, 0x72, 0x65, 0x65,
+	0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x06,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x6b, 0x0a, 0x18, 0x4c, 0x69, 0x73, 0x74, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x49, 0x64, 0x12, 0x34, 0x0a,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e,
+	0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x68,
+	0x65, 0x64, 0x75, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x22, 0x2b, 0x0a, 0x15, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x65, 0x61,
+	0x63, 0x68, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x22, 0x2e, 0x0a, 0x16, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x65, 0x61, 0x63, 0x68, 0x65,
+	0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x22, 0x75, 0x0a, 0x1a, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x53,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21,
+	0x0a, 0x0c, 0x74, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x4e, 0x61, 0x6d,
+	0x65, 0x12, 0x34, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1c, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52,
+	0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2a, 0x59, 0x0a, 0x08, 0x57, 0x65, 0x65, 0x6b, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x45, 0x45, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x17,
+	0x0a, 0x13, 0x57, 0x45, 0x45, 0x4b, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4e, 0x55, 0x4d, 0x45,
+	0x52, 0x41, 0x54, 0x4f, 0x52, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x57, 0x45, 0x45, 0x4b, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x45, 0x4e, 0x4f, 0x4d, 0x49, 0x4e, 0x41, 0x54, 0x4f, 0x52,
+	0x10, 0x02, 0x32, 0xbb, 0x04, 0x0a, 0x09, 0x46, 0x72, 0x65, 0x65, 0x52, 0x6f, 0x6f, 0x6d, 0x73,
+	0x12, 0x5c, 0x0a, 0x12, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x41, 0x75, 0x64,
+	0x69, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x27, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f,
+	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x41,
+	0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1b, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x30, 0x01, 0x12, 0x60,
+	0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e, 0x63, 0x65, 0x53, 0x63,
+	0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x29, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f,
+	0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x75, 0x64, 0x69, 0x65, 0x6e,
+	0x63, 0x65, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x30, 0x01,
+	0x12, 0x55, 0x0a, 0x0c, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x12, 0x21, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e,
+	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5a, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x47,
+	0x72, 0x6f, 0x75, 0x70, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x26, 0x2e, 0x66,
+	0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x47, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x30, 0x01, 0x12, 0x5b, 0x0a, 0x0e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x65, 0x61,
+	0x63, 0x68, 0x65, 0x72, 0x73, 0x12, 0x23, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d,
+	0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x54, 0x65, 0x61, 0x63, 0x68,
+	0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x66, 0x72, 0x65,
+	0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x54, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x5e, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x61, 0x63, 0x68, 0x65, 0x72, 0x53,
+	0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x28, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f,
+	0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x61, 0x63, 0x68,
+	0x65, 0x72, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x30, 0x01,
+	0x42, 0x3b, 0x5a, 0x39, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x41,
+	0x6c, 0x65, 0x78, 0x69, 0x73, 0x4f, 0x4d, 0x47, 0x2f, 0x62, 0x6d, 0x73, 0x74, 0x75, 0x2d, 0x66,
+	0x72, 0x65, 0x65, 0x2d, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x61, 0x70,
+	0x69, 0x2f, 0x66, 0x72, 0x65, 0x65, 0x72, 0x6f, 0x6f, 0x6d, 0x73, 0x70, 0x62, 0x62, 0x06, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_freerooms_proto_rawDescOnce sync.Once
+	file_freerooms_proto_rawDescData = file_freerooms_proto_rawDesc
+)
+
+func file_freerooms_proto_rawDescGZIP() []byte {
+	file_freerooms_proto_rawDescOnce.Do(func() {
+		file_freerooms_proto_rawDescData = protoimpl.X.CompressGZIP(file_freerooms_proto_rawDescData)
+	})
+	return file_freerooms_proto_rawDescData
+}
+
+var file_freerooms_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_freerooms_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_freerooms_proto_goTypes = []interface{}{
+	(WeekType)(0),                       // 0: freerooms.v1.WeekType
+	(*Audience)(nil),                    // 1: freerooms.v1.Audience
+	(*ListEmptyAudiencesRequest)(nil),   // 2: freerooms.v1.ListEmptyAudiencesRequest
+	(*EmptyAudience)(nil),               // 3: freerooms.v1.EmptyAudience
+	(*Lesson)(nil),                      // 4: freerooms.v1.Lesson
+	(*ScheduleEntry)(nil),               // 5: freerooms.v1.ScheduleEntry
+	(*ScheduleFilter)(nil),              // 6: freerooms.v1.ScheduleFilter
+	(*ListAudienceScheduleRequest)(nil), // 7: freerooms.v1.ListAudienceScheduleRequest
+	(*Group)(nil),                       // 8: freerooms.v1.Group
+	(*SearchGroupsRequest)(nil),         // 9: freerooms.v1.SearchGroupsRequest
+	(*SearchGroupsResponse)(nil),        // 10: freerooms.v1.SearchGroupsResponse
+	(*ListGroupScheduleRequest)(nil),    // 11: freerooms.v1.ListGroupScheduleRequest
+	(*SearchTeachersRequest)(nil),       // 12: freerooms.v1.SearchTeachersRequest
+	(*SearchTeachersResponse)(nil),      // 13: freerooms.v1.SearchTeachersResponse
+	(*ListTeacherScheduleRequest)(nil),  // 14: freerooms.v1.ListTeacherScheduleRequest
+	(*timestamppb.Timestamp)(nil),       // 15: google.protobuf.Timestamp
+}
+var file_freerooms_proto_depIdxs = []int32{
+	0,  // 0: freerooms.v1.ListEmptyAudiencesRequest.week_type:type_name -> freerooms.v1.WeekType
+	15, // 1: freerooms.v1.ListEmptyAudiencesRequest.claims_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: freerooms.v1.EmptyAudience.audience:type_name -> freerooms.v1.Audience
+	15, // 3: freerooms.v1.EmptyAudience.claimed_until:type_name -> google.protobuf.Timestamp
+	0,  // 4: freerooms.v1.ScheduleEntry.week_type:type_name -> freerooms.v1.WeekType
+	4,  // 5: freerooms.v1.ScheduleEntry.lesson:type_name -> freerooms.v1.Lesson
+	1,  // 6: freerooms.v1.ScheduleEntry.audience:type_name -> freerooms.v1.Audience
+	0,  // 7: freerooms.v1.ScheduleFilter.week_type:type_name -> freerooms.v1.WeekType
+	6,  // 8: freerooms.v1.ListAudienceScheduleRequest.filter:type_name -> freerooms.v1.ScheduleFilter
+	8,  // 9: freerooms.v1.SearchGroupsResponse.groups:type_name -> freerooms.v1.Group
+	6,  // 10: freerooms.v1.ListGroupScheduleRequest.filter:type_name -> freerooms.v1.ScheduleFilter
+	6,  // 11: freerooms.v1.ListTeacherScheduleRequest.filter:type_name -> freerooms.v1.ScheduleFilter
+	2,  // 12: freerooms.v1.FreeRooms.ListEmptyAudiences:input_type -> freerooms.v1.ListEmptyAudiencesRequest
+	7,  // 13: freerooms.v1.FreeRooms.ListAudienceSchedule:input_type -> freerooms.v1.ListAudienceScheduleRequest
+	9,  // 14: freerooms.v1.FreeRooms.SearchGroups:input_type -> freerooms.v1.SearchGroupsRequest
+	11, // 15: freerooms.v1.FreeRooms.ListGroupSchedule:input_type -> freerooms.v1.ListGroupScheduleRequest
+	12, // 16: freerooms.v1.FreeRooms.SearchTeachers:input_type -> freerooms.v1.SearchTeachersRequest
+	14, // 17: freerooms.v1.FreeRooms.ListTeacherSchedule:input_type -> freerooms.v1.ListTeacherScheduleRequest
+	3,  // 18: freerooms.v1.FreeRooms.ListEmptyAudiences:output_type -> freerooms.v1.EmptyAudience
+	5,  // 19: freerooms.v1.FreeRooms.ListAudienceSchedule:output_type -> freerooms.v1.ScheduleEntry
+	10, // 20: freerooms.v1.FreeRooms.SearchGroups:output_type -> freerooms.v1.SearchGroupsResponse
+	5,  // 21: freerooms.v1.FreeRooms.ListGroupSchedule:output_type -> freerooms.v1.ScheduleEntry
+	13, // 22: freerooms.v1.FreeRooms.SearchTeachers:output_type -> freerooms.v1.SearchTeachersResponse
+	5,  // 23: freerooms.v1.FreeRooms.ListTeacherSchedule:output_type -> freerooms.v1.ScheduleEntry
+	18, // [18:24] is the sub-list for method output_type
+	12, // [12:18] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_freerooms_proto_init() }
+func file_freerooms_proto_init() {
+	if File_freerooms_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_freerooms_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Audience); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListEmptyAudiencesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EmptyAudience); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Lesson); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleEntry); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListAudienceScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Group); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchGroupsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchGroupsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListGroupScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchTeachersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SearchTeachersResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_freerooms_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListTeacherScheduleRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_freerooms_proto_msgTypes[0].OneofWrappers = []interface{}{}
+	file_freerooms_proto_msgTypes[3].OneofWrappers = []interface{}{}
+	file_freerooms_proto_msgTypes[5].OneofWrappers = []interface{}{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_freerooms_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_freerooms_proto_goTypes,
+		DependencyIndexes: file_freerooms_proto_depIdxs,
+		EnumInfos:         file_freerooms_proto_enumTypes,
+		MessageInfos:      file_freerooms_proto_msgTypes,
+	}.Build()
+	File_freerooms_proto = out.File
+	file_freerooms_proto_rawDesc = nil
+	file_freerooms_proto_goTypes = nil
+	file_freerooms_proto_depIdxs = nil
+}
@@ -0,0 +1,417 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: freerooms.proto
+
+package freeroomspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	FreeRooms_ListEmptyAudiences_FullMethodName   = "/freerooms.v1.FreeRooms/ListEmptyAudiences"
+	FreeRooms_ListAudienceSchedule_FullMethodName = "/freerooms.v1.FreeRooms/ListAudienceSchedule"
+	FreeRooms_SearchGroups_FullMethodName         = "/freerooms.v1.FreeRooms/SearchGroups"
+	FreeRooms_ListGroupSchedule_FullMethodName    = "/freerooms.v1.FreeRooms/ListGroupSchedule"
+	FreeRooms_SearchTeachers_FullMethodName       = "/freerooms.v1.FreeRooms/SearchTeachers"
+	FreeRooms_ListTeacherSchedule_FullMethodName  = "/freerooms.v1.FreeRooms/ListTeacherSchedule"
+)
+
+// FreeRoomsClient is the client API for FreeRooms service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FreeRoomsClient interface {
+	// ListEmptyAudiences streams audiences free for every requested period on every requested day,
+	// ordered by building, floor and number.
+	ListEmptyAudiences(ctx context.Context, in *ListEmptyAudiencesRequest, opts ...grpc.CallOption) (FreeRooms_ListEmptyAudiencesClient, error)
+	// ListAudienceSchedule streams lessons in the audience, NOT_FOUND if there is no such audience.
+	ListAudienceSchedule(ctx context.Context, in *ListAudienceScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListAudienceScheduleClient, error)
+	// SearchGroups finds groups by a sloppy name the way the bot does, NOT_FOUND if none match.
+	SearchGroups(ctx context.Context, in *SearchGroupsRequest, opts ...grpc.CallOption) (*SearchGroupsResponse, error)
+	// ListGroupSchedule streams lessons of the group, find its ID with SearchGroups.
+	ListGroupSchedule(ctx context.Context, in *ListGroupScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListGroupScheduleClient, error)
+	// SearchTeachers finds teachers whose names contain every word of the query, NOT_FOUND if none match.
+	SearchTeachers(ctx context.Context, in *SearchTeachersRequest, opts ...grpc.CallOption) (*SearchTeachersResponse, error)
+	// ListTeacherSchedule streams lessons of the teacher, the name must be exact, see SearchTeachers.
+	ListTeacherSchedule(ctx context.Context, in *ListTeacherScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListTeacherScheduleClient, error)
+}
+
+type freeRoomsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFreeRoomsClient(cc grpc.ClientConnInterface) FreeRoomsClient {
+	return &freeRoomsClient{cc}
+}
+
+func (c *freeRoomsClient) ListEmptyAudiences(ctx context.Context, in *ListEmptyAudiencesRequest, opts ...grpc.CallOption) (FreeRooms_ListEmptyAudiencesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FreeRooms_ServiceDesc.Streams[0], FreeRooms_ListEmptyAudiences_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &freeRoomsListEmptyAudiencesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FreeRooms_ListEmptyAudiencesClient interface {
+	Recv() (*EmptyAudience, error)
+	grpc.ClientStream
+}
+
+type freeRoomsListEmptyAudiencesClient struct {
+	grpc.ClientStream
+}
+
+func (x *freeRoomsListEmptyAudiencesClient) Recv() (*EmptyAudience, error) {
+	m := new(EmptyAudience)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *freeRoomsClient) ListAudienceSchedule(ctx context.Context, in *ListAudienceScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListAudienceScheduleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FreeRooms_ServiceDesc.Streams[1], FreeRooms_ListAudienceSchedule_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &freeRoomsListAudienceScheduleClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FreeRooms_ListAudienceScheduleClient interface {
+	Recv() (*ScheduleEntry, error)
+	grpc.ClientStream
+}
+
+type freeRoomsListAudienceScheduleClient struct {
+	grpc.ClientStream
+}
+
+func (x *freeRoomsListAudienceScheduleClient) Recv() (*ScheduleEntry, error) {
+	m := new(ScheduleEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *freeRoomsClient) SearchGroups(ctx context.Context, in *SearchGroupsRequest, opts ...grpc.CallOption) (*SearchGroupsResponse, error) {
+	out := new(SearchGroupsResponse)
+	err := c.cc.Invoke(ctx, FreeRooms_SearchGroups_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *freeRoomsClient) ListGroupSchedule(ctx context.Context, in *ListGroupScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListGroupScheduleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FreeRooms_ServiceDesc.Streams[2], FreeRooms_ListGroupSchedule_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &freeRoomsListGroupScheduleClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FreeRooms_ListGroupScheduleClient interface {
+	Recv() (*ScheduleEntry, error)
+	grpc.ClientStream
+}
+
+type freeRoomsListGroupScheduleClient struct {
+	grpc.ClientStream
+}
+
+func (x *freeRoomsListGroupScheduleClient) Recv() (*ScheduleEntry, error) {
+	m := new(ScheduleEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *freeRoomsClient) SearchTeachers(ctx context.Context, in *SearchTeachersRequest, opts ...grpc.CallOption) (*SearchTeachersResponse, error) {
+	out := new(SearchTeachersResponse)
+	err := c.cc.Invoke(ctx, FreeRooms_SearchTeachers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *freeRoomsClient) ListTeacherSchedule(ctx context.Context, in *ListTeacherScheduleRequest, opts ...grpc.CallOption) (FreeRooms_ListTeacherScheduleClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FreeRooms_ServiceDesc.Streams[3], FreeRooms_ListTeacherSchedule_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &freeRoomsListTeacherScheduleClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FreeRooms_ListTeacherScheduleClient interface {
+	Recv() (*ScheduleEntry, error)
+	grpc.ClientStream
+}
+
+type freeRoomsListTeacherScheduleClient struct {
+	grpc.ClientStream
+}
+
+func (x *freeRoomsListTeacherScheduleClient) Recv() (*ScheduleEntry, error) {
+	m := new(ScheduleEntry)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FreeRoomsServer is the server API for FreeRooms service.
+// All implementations must embed UnimplementedFreeRoomsServer
+// for forward compatibility
+type FreeRoomsServer interface {
+	// ListEmptyAudiences streams audiences free for every requested period on every requested day,
+	// ordered by building, floor and number.
+	ListEmptyAudiences(*ListEmptyAudiencesRequest, FreeRooms_ListEmptyAudiencesServer) error
+	// ListAudienceSchedule streams lessons in the audience, NOT_FOUND if there is no such audience.
+	ListAudienceSchedule(*ListAudienceScheduleRequest, FreeRooms_ListAudienceScheduleServer) error
+	// SearchGroups finds groups by a sloppy name the way the bot does, NOT_FOUND if none match.
+	SearchGroups(context.Context, *SearchGroupsRequest) (*SearchGroupsResponse, error)
+	// ListGroupSchedule streams lessons of the group, find its ID with SearchGroups.
+	ListGroupSchedule(*ListGroupScheduleRequest, FreeRooms_ListGroupScheduleServer) error
+	// SearchTeachers finds teachers whose names contain every word of the query, NOT_FOUND if none match.
+	SearchTeachers(context.Context, *SearchTeachersRequest) (*SearchTeachersResponse, error)
+	// ListTeacherSchedule streams lessons of the teacher, the name must be exact, see SearchTeachers.
+	ListTeacherSchedule(*ListTeacherScheduleRequest, FreeRooms_ListTeacherScheduleServer) error
+	mustEmbedUnimplementedFreeRoomsServer()
+}
+
+// UnimplementedFreeRoomsServer must be embedded to have forward compatible implementations.
+type UnimplementedFreeRoomsServer struct {
+}
+
+func (UnimplementedFreeRoomsServer) ListEmptyAudiences(*ListEmptyAudiencesRequest, FreeRooms_ListEmptyAudiencesServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListEmptyAudiences not implemented")
+}
+func (UnimplementedFreeRoomsServer) ListAudienceSchedule(*ListAudienceScheduleRequest, FreeRooms_ListAudienceScheduleServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListAudienceSchedule not implemented")
+}
+func (UnimplementedFreeRoomsServer) SearchGroups(context.Context, *SearchGroupsRequest) (*SearchGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchGroups not implemented")
+}
+func (UnimplementedFreeRoomsServer) ListGroupSchedule(*ListGroupScheduleRequest, FreeRooms_ListGroupScheduleServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListGroupSchedule not implemented")
+}
+func (UnimplementedFreeRoomsServer) SearchTeachers(context.Context, *SearchTeachersRequest) (*SearchTeachersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTeachers not implemented")
+}
+func (UnimplementedFreeRoomsServer) ListTeacherSchedule(*ListTeacherScheduleRequest, FreeRooms_ListTeacherScheduleServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListTeacherSchedule not implemented")
+}
+func (UnimplementedFreeRoomsServer) mustEmbedUnimplementedFreeRoomsServer() {}
+
+// UnsafeFreeRoomsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FreeRoomsServer will
+// result in compilation errors.
+type UnsafeFreeRoomsServer interface {
+	mustEmbedUnimplementedFreeRoomsServer()
+}
+
+func RegisterFreeRoomsServer(s grpc.ServiceRegistrar, srv FreeRoomsServer) {
+	s.RegisterService(&FreeRooms_ServiceDesc, srv)
+}
+
+func _FreeRooms_ListEmptyAudiences_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListEmptyAudiencesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FreeRoomsServer).ListEmptyAudiences(m, &freeRoomsListEmptyAudiencesServer{stream})
+}
+
+type FreeRooms_ListEmptyAudiencesServer interface {
+	Send(*EmptyAudience) error
+	grpc.ServerStream
+}
+
+type freeRoomsListEmptyAudiencesServer struct {
+	grpc.ServerStream
+}
+
+func (x *freeRoomsListEmptyAudiencesServer) Send(m *EmptyAudience) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FreeRooms_ListAudienceSchedule_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListAudienceScheduleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FreeRoomsServer).ListAudienceSchedule(m, &freeRoomsListAudienceScheduleServer{stream})
+}
+
+type FreeRooms_ListAudienceScheduleServer interface {
+	Send(*ScheduleEntry) error
+	grpc.ServerStream
+}
+
+type freeRoomsListAudienceScheduleServer struct {
+	grpc.ServerStream
+}
+
+func (x *freeRoomsListAudienceScheduleServer) Send(m *ScheduleEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FreeRooms_SearchGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FreeRoomsServer).SearchGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FreeRooms_SearchGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FreeRoomsServer).SearchGroups(ctx, req.(*SearchGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FreeRooms_ListGroupSchedule_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListGroupScheduleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FreeRoomsServer).ListGroupSchedule(m, &freeRoomsListGroupScheduleServer{stream})
+}
+
+type FreeRooms_ListGroupScheduleServer interface {
+	Send(*ScheduleEntry) error
+	grpc.ServerStream
+}
+
+type freeRoomsListGroupScheduleServer struct {
+	grpc.ServerStream
+}
+
+func (x *freeRoomsListGroupScheduleServer) Send(m *ScheduleEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FreeRooms_SearchTeachers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTeachersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FreeRoomsServer).SearchTeachers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FreeRooms_SearchTeachers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FreeRoomsServer).SearchTeachers(ctx, req.(*SearchTeachersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FreeRooms_ListTeacherSchedule_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListTeacherScheduleRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FreeRoomsServer).ListTeacherSchedule(m, &freeRoomsListTeacherScheduleServer{stream})
+}
+
+type FreeRooms_ListTeacherScheduleServer interface {
+	Send(*ScheduleEntry) error
+	grpc.ServerStream
+}
+
+type freeRoomsListTeacherScheduleServer struct {
+	grpc.ServerStream
+}
+
+func (x *freeRoomsListTeacherScheduleServer) Send(m *ScheduleEntry) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// FreeRooms_ServiceDesc is the grpc.ServiceDesc for FreeRooms service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FreeRooms_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "freerooms.v1.FreeRooms",
+	HandlerType: (*FreeRoomsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SearchGroups",
+			Handler:    _FreeRooms_SearchGroups_Handler,
+		},
+		{
+			MethodName: "SearchTeachers",
+			Handler:    _FreeRooms_SearchTeachers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListEmptyAudiences",
+			Handler:       _FreeRooms_ListEmptyAudiences_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListAudienceSchedule",
+			Handler:       _FreeRooms_ListAudienceSchedule_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListGroupSchedule",
+			Handler:       _FreeRooms_ListGroupSchedule_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ListTeacherSchedule",
+			Handler:       _FreeRooms_ListTeacherSchedule_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "freerooms.proto",
+}
@@ -0,0 +1,225 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/AlexisOMG/bmstu-free-rooms/grpcapi/freeroomspb"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+func audienceToPB(a service.Audience) *pb.Audience {
+	res := &pb.Audience{
+		Id:        a.ID,
+		Number:    a.Number,
+		Building:  a.Building,
+		Floor:     int32(a.Floor),
+		Suffix:    a.Suffix,
+		Kind:      a.Kind,
+		Equipment: a.Equipment,
+	}
+	if a.Capacity != nil {
+		capacity := int32(*a.Capacity)
+		res.Capacity = &capacity
+	}
+	return res
+}
+
+func scheduleEntryToPB(e service.ScheduleEntry) *pb.ScheduleEntry {
+	res := &pb.ScheduleEntry{
+		ScheduleId: e.ScheduleID,
+		WeekType:   weekTypeToPB(e.WeekType),
+		WeekDay:    e.WeekDay,
+		Period:     int32(e.Period),
+		Lesson: &pb.Lesson{
+			Id:          e.Lesson.ID,
+			Name:        e.Lesson.Name,
+			TeacherName: e.Lesson.TeacherName,
+			Kind:        e.Lesson.Kind,
+		},
+		Audience: audienceToPB(e.Audience),
+		Groups:   e.Groups,
+	}
+	if b, ok := service.BellByPeriod(e.Period); ok {
+		res.Start, res.End = b.StartString(), b.EndString()
+	}
+	return res
+}
+
+// emptyAudiencesFilter fills the week type, the days and the periods the request leaves out
+// with the current or the next period, as the REST API does.
+func (s *Server) emptyAudiencesFilter(req *pb.ListEmptyAudiencesRequest, now time.Time) (*service.EmptyAudiencesFilter, error) {
+	slot := s.calendar.CurrentSlot(now)
+	filter := &service.EmptyAudiencesFilter{
+		Buildings:   req.GetBuildings(),
+		Floors:      ints(req.GetFloors()),
+		WeekType:    slot.WeekType,
+		Periods:     ints(req.GetPeriods()),
+		HideClaimed: req.GetHideClaimed(),
+		MinCapacity: int(req.GetMinCapacity()),
+		Kinds:       req.GetKinds(),
+		Equipment:   req.GetEquipment(),
+	}
+	if wt := weekTypeFromPB(req.GetWeekType()); wt != nil {
+		filter.WeekType = *wt
+	}
+	for _, d := range req.GetWeekDays() {
+		day, err := weekDay(d)
+		if err != nil {
+			return nil, err
+		}
+		filter.WeekDays = append(filter.WeekDays, day)
+	}
+	if len(filter.WeekDays) == 0 {
+		filter.WeekDays = []string{slot.WeekDay}
+	}
+	if len(filter.Periods) == 0 {
+		filter.Periods = []int{slot.Period}
+	}
+	if req.GetMinCapacity() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "negative min_capacity %d", req.GetMinCapacity())
+	}
+	if req.GetClaimsAt() != nil {
+		claimsAt := req.GetClaimsAt().AsTime()
+		filter.ClaimsAt = &claimsAt
+	}
+	return filter, nil
+}
+
+func (s *Server) ListEmptyAudiences(req *pb.ListEmptyAudiencesRequest, stream pb.FreeRooms_ListEmptyAudiencesServer) error {
+	filter, err := s.emptyAudiencesFilter(req, time.Now())
+	if err != nil {
+		return err
+	}
+
+	audiences, err := s.srvc.ListEmptyAudiences(stream.Context(), filter)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range audiences {
+		res := &pb.EmptyAudience{
+			Audience:              audienceToPB(a.Audience),
+			NextBusyPeriod:        int32(a.NextBusyPeriod),
+			UnavailableConfidence: a.UnavailableConfidence,
+			Suspicious:            a.Suspicious(),
+		}
+		if a.ClaimedUntil != nil {
+			res.ClaimedUntil = timestamppb.New(*a.ClaimedUntil)
+		}
+		if err := stream.Send(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scheduleFilter turns the optional filter of a request into the week type and day of the service.
+func scheduleFilter(f *pb.ScheduleFilter) (weekType, weekDayFilter *string, err error) {
+	weekType = weekTypeFromPB(f.GetWeekType())
+	if f.WeekDay != nil {
+		day, err := weekDay(f.GetWeekDay())
+		if err != nil {
+			return nil, nil, err
+		}
+		weekDayFilter = &day
+	}
+	return weekType, weekDayFilter, nil
+}
+
+type scheduleStream interface {
+	Context() context.Context
+	Send(*pb.ScheduleEntry) error
+}
+
+func sendSchedule(stream scheduleStream, entries []service.ScheduleEntry) error {
+	for _, e := range entries {
+		if err := stream.Send(scheduleEntryToPB(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) ListAudienceSchedule(req *pb.ListAudienceScheduleRequest, stream pb.FreeRooms_ListAudienceScheduleServer) error {
+	if err := invalidID("audience", req.GetAudienceId()); err != nil {
+		return err
+	}
+	weekType, weekDay, err := scheduleFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.srvc.GetAudience(stream.Context(), req.GetAudienceId()); err != nil {
+		return err
+	}
+	entries, err := s.srvc.ListScheduleEntries(stream.Context(), &service.ScheduleEntryFilters{
+		AudienceIDs: []string{req.GetAudienceId()},
+		WeekType:    weekType,
+		WeekDay:     weekDay,
+	})
+	if err != nil {
+		return err
+	}
+	return sendSchedule(stream, entries)
+}
+
+func (s *Server) SearchGroups(ctx context.Context, req *pb.SearchGroupsRequest) (*pb.SearchGroupsResponse, error) {
+	groups, err := s.srvc.SearchGroups(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &pb.SearchGroupsResponse{Groups: make([]*pb.Group, 0, len(groups))}
+	for _, g := range groups {
+		res.Groups = append(res.Groups, &pb.Group{Id: g.ID, Name: g.Name})
+	}
+	return res, nil
+}
+
+func (s *Server) ListGroupSchedule(req *pb.ListGroupScheduleRequest, stream pb.FreeRooms_ListGroupScheduleServer) error {
+	if err := invalidID("group", req.GetGroupId()); err != nil {
+		return err
+	}
+	weekType, weekDay, err := scheduleFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.srvc.GetGroup(stream.Context(), req.GetGroupId()); err != nil {
+		return err
+	}
+	entries, err := s.srvc.ListGroupSchedule(stream.Context(), req.GetGroupId(), weekType, weekDay)
+	if err != nil {
+		return err
+	}
+	return sendSchedule(stream, entries)
+}
+
+func (s *Server) SearchTeachers(ctx context.Context, req *pb.SearchTeachersRequest) (*pb.SearchTeachersResponse, error) {
+	names, err := s.srvc.SearchTeachers(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.SearchTeachersResponse{Names: names}, nil
+}
+
+func (s *Server) ListTeacherSchedule(req *pb.ListTeacherScheduleRequest, stream pb.FreeRooms_ListTeacherScheduleServer) error {
+	if req.GetTeacherName() == "" {
+		return status.Error(codes.InvalidArgument, "empty teacher name")
+	}
+	weekType, weekDay, err := scheduleFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	entries, err := s.srvc.ListTeacherSchedule(stream.Context(), req.GetTeacherName(), weekType, weekDay)
+	if err != nil {
+		return err
+	}
+	return sendSchedule(stream, entries)
+}
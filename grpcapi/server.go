@@ -0,0 +1,187 @@
+// Package grpcapi serves free audience and timetable queries over gRPC for our other Go services,
+// freerooms.proto describes the service and freeroomspb holds the generated code.
+package grpcapi
+
+//go:generate protoc --go_out=freeroomspb --go_opt=paths=source_relative --go-grpc_out=freeroomspb --go-grpc_opt=paths=source_relative freerooms.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/AlexisOMG/bmstu-free-rooms/grpcapi/freeroomspb"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+type Config struct {
+	// Listen is the address of the gRPC server, e.g. ":9090"
+	Listen string `yaml:"listen"`
+}
+
+type Server struct {
+	pb.UnimplementedFreeRoomsServer
+
+	srvc     *service.Service
+	calendar *service.Calendar
+	logger   *logrus.Logger
+	conf     Config
+}
+
+func NewServer(srvc *service.Service, calendar *service.Calendar, logger *logrus.Logger, conf Config) *Server {
+	return &Server{
+		srvc:     srvc,
+		calendar: calendar,
+		logger:   logger,
+		conf:     conf,
+	}
+}
+
+// Run serves gRPC until ctx is done, then lets running calls finish for a while.
+func (s *Server) Run(ctx context.Context) error {
+	lis, err := net.Listen("tcp", s.conf.Listen)
+	if err != nil {
+		return fmt.Errorf("cannot listen: %w", err)
+	}
+
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(s.unaryErrors),
+		grpc.StreamInterceptor(s.streamErrors),
+	)
+	pb.RegisterFreeRoomsServer(server, s)
+
+	go func() {
+		<-ctx.Done()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			server.Stop()
+		}
+	}()
+
+	s.logger.WithField("addr", s.conf.Listen).Info("serving grpc")
+	if err := server.Serve(lis); err != nil {
+		return fmt.Errorf("grpc server failed: %w", err)
+	}
+	return nil
+}
+
+// toStatus maps service errors to gRPC codes, errors which already have a status are kept.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, validationErr.Message)
+	case errors.Is(err, service.ErrorNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, service.ErrorTransient):
+		return status.Error(codes.Unavailable, "try again later")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func (s *Server) logInternal(method string, err error) {
+	if status.Code(err) == codes.Internal {
+		s.logger.WithError(err).WithField("method", method).Error("grpc call failed")
+	}
+}
+
+// recoverPanic turns a panic in a handler into codes.Internal, the server shares the process with the bot.
+func (s *Server) recoverPanic(method string, err *error) {
+	if r := recover(); r != nil {
+		s.logger.WithField("method", method).WithField("stack", string(debug.Stack())).Errorf("panic in grpc call: %v", r)
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+func (s *Server) unaryErrors(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	defer s.recoverPanic(info.FullMethod, &err)
+	resp, err = handler(ctx, req)
+	if err != nil {
+		s.logInternal(info.FullMethod, toStatus(err))
+		return nil, toStatus(err)
+	}
+	return resp, nil
+}
+
+func (s *Server) streamErrors(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer s.recoverPanic(info.FullMethod, &err)
+	if err := handler(srv, ss); err != nil {
+		s.logInternal(info.FullMethod, toStatus(err))
+		return toStatus(err)
+	}
+	return nil
+}
+
+func invalidID(kind, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid %s id %q", kind, id)
+	}
+	return nil
+}
+
+func weekTypeFromPB(wt pb.WeekType) *string {
+	var res string
+	switch wt {
+	case pb.WeekType_WEEK_TYPE_NUMERATOR:
+		res = service.WeekTypeNumerator
+	case pb.WeekType_WEEK_TYPE_DENOMINATOR:
+		res = service.WeekTypeDenominator
+	default:
+		return nil
+	}
+	return &res
+}
+
+func weekTypeToPB(wt string) pb.WeekType {
+	switch wt {
+	case service.WeekTypeNumerator:
+		return pb.WeekType_WEEK_TYPE_NUMERATOR
+	case service.WeekTypeDenominator:
+		return pb.WeekType_WEEK_TYPE_DENOMINATOR
+	default:
+		return pb.WeekType_WEEK_TYPE_UNSPECIFIED
+	}
+}
+
+// weekDay accepts english names in any case, e.g. monday.
+func weekDay(v string) (string, error) {
+	for _, d := range service.WeekDays() {
+		if strings.EqualFold(d, v) {
+			return d, nil
+		}
+	}
+	return "", status.Errorf(codes.InvalidArgument, "unknown week day %q", v)
+}
+
+func ints(values []int32) []int {
+	res := make([]int, 0, len(values))
+	for _, v := range values {
+		res = append(res, int(v))
+	}
+	return res
+}
@@ -0,0 +1,148 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/AlexisOMG/bmstu-free-rooms/grpcapi/freeroomspb"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+func newTestServer() *Server {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	// the semester starts on Monday, August 31, a ЧС week
+	calendar := service.NewCalendar(time.Date(2026, time.August, 31, 0, 0, 0, 0, service.MoscowLocation))
+	return NewServer(nil, calendar, logger, Config{})
+}
+
+func TestUnaryErrors(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/freerooms.v1.FreeRooms/GetAudience"}
+	tests := []struct {
+		name    string
+		handler grpc.UnaryHandler
+		code    codes.Code
+	}{
+		{
+			name:    "success",
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil },
+			code:    codes.OK,
+		},
+		{
+			name: "panic",
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				var a *pb.Audience
+				return a.Id, nil
+			},
+			code: codes.Internal,
+		},
+		{
+			name:    "not found",
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) { return nil, service.ErrorNotFound },
+			code:    codes.NotFound,
+		},
+		{
+			name:    "other error",
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) { return nil, errors.New("broken") },
+			code:    codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestServer().unaryErrors(context.Background(), nil, info, tt.handler)
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("unaryErrors() code = %v, want %v (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestStreamErrorsRecoversPanic(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/freerooms.v1.FreeRooms/ListEmptyAudiences"}
+	err := newTestServer().streamErrors(nil, nil, info, func(srv interface{}, stream grpc.ServerStream) error {
+		panic("boom")
+	})
+	if got := status.Code(err); got != codes.Internal {
+		t.Errorf("streamErrors() code = %v, want %v", got, codes.Internal)
+	}
+}
+
+func TestEmptyAudiencesFilter(t *testing.T) {
+	// Tuesday of the first, ЧС, week during the second period
+	now := time.Date(2026, time.September, 1, 10, 30, 0, 0, service.MoscowLocation)
+	claimsAt := time.Date(2026, time.September, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  *pb.ListEmptyAudiencesRequest
+		want service.EmptyAudiencesFilter
+		code codes.Code
+	}{
+		{
+			name: "current period by default",
+			req:  &pb.ListEmptyAudiencesRequest{},
+			want: service.EmptyAudiencesFilter{
+				Floors: []int{}, WeekType: service.WeekTypeNumerator, WeekDays: []string{"Tuesday"}, Periods: []int{2},
+			},
+		},
+		{
+			name: "periods without days are today",
+			req:  &pb.ListEmptyAudiencesRequest{Periods: []int32{4, 5}},
+			want: service.EmptyAudiencesFilter{
+				Floors: []int{}, WeekType: service.WeekTypeNumerator, WeekDays: []string{"Tuesday"}, Periods: []int{4, 5},
+			},
+		},
+		{
+			name: "days without periods take the current one",
+			req:  &pb.ListEmptyAudiencesRequest{WeekDays: []string{"friday"}, WeekType: pb.WeekType_WEEK_TYPE_DENOMINATOR},
+			want: service.EmptyAudiencesFilter{
+				Floors: []int{}, WeekType: service.WeekTypeDenominator, WeekDays: []string{"Friday"}, Periods: []int{2},
+			},
+		},
+		{
+			name: "claims at the given moment",
+			req:  &pb.ListEmptyAudiencesRequest{Buildings: []string{"ГЗ"}, Floors: []int32{3}, ClaimsAt: timestamppb.New(claimsAt)},
+			want: service.EmptyAudiencesFilter{
+				Buildings: []string{"ГЗ"}, Floors: []int{3}, WeekType: service.WeekTypeNumerator,
+				WeekDays: []string{"Tuesday"}, Periods: []int{2}, ClaimsAt: &claimsAt,
+			},
+		},
+		{
+			name: "unknown day",
+			req:  &pb.ListEmptyAudiencesRequest{WeekDays: []string{"someday"}},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "negative capacity",
+			req:  &pb.ListEmptyAudiencesRequest{MinCapacity: -1},
+			code: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestServer().emptyAudiencesFilter(tt.req, now)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("emptyAudiencesFilter() code = %v, want %v (%v)", code, tt.code, err)
+			}
+			if err != nil {
+				return
+			}
+			if got.ClaimsAt != nil && tt.want.ClaimsAt != nil && got.ClaimsAt.Equal(*tt.want.ClaimsAt) {
+				got.ClaimsAt = tt.want.ClaimsAt
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("emptyAudiencesFilter() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// countedCommands are the commands /stats tells apart, others are counted together.
+var countedCommands = map[string]bool{
+	"start": true, "now": true, "room": true, "group": true, "teacher": true, "settings": true,
+	"fav": true, "unfav": true, "favs": true, "claim": true, "release": true, "report": true,
+}
+
+const (
+	// broadcastSenders is how many broadcast messages wait in the send queue at once,
+	// the queue itself keeps them within the rate limits.
+	broadcastSenders = 8
+	// adminTaskGracePeriod is how long shutdown waits for a running /reload or /broadcast before cancelling it
+	adminTaskGracePeriod = 30 * time.Second
+)
+
+// isAdmin tells whether the user is listed in the config or marked as admin in user_info.
+func (tb *telegramBot) isAdmin(ctx context.Context, from *tgbotapi.User) (bool, error) {
+	if from == nil {
+		return false, nil
+	}
+	if tb.conf != nil {
+		for _, id := range tb.conf.Admins {
+			if id == from.ID {
+				return true, nil
+			}
+		}
+	}
+
+	users, err := tb.srvc.ListUsers(ctx, &service.UserFilters{TelegramIDs: []string{strconv.FormatInt(from.ID, 10)}})
+	if errors.Is(err, service.ErrorNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get user: %w", err)
+	}
+	return len(users) > 0 && users[0].IsAdmin, nil
+}
+
+// handleAdminCommand serves admin commands, for other users they do not exist.
+func (tb *telegramBot) handleAdminCommand(ctx context.Context, message *tgbotapi.Message) error {
+	admin, err := tb.isAdmin(ctx, message.From)
+	if err != nil {
+		return err
+	}
+	if !admin {
+		tb.logger.WithField("from", message.From).Warning("admin command from non-admin")
+		return nil
+	}
+
+	switch message.Command() {
+	case "reload":
+		return tb.handleReload(ctx, message)
+	case "stats":
+		return tb.handleStats(ctx, message)
+	case "broadcast":
+		return tb.handleBroadcast(ctx, message)
+	case "import_status":
+		return tb.handleImportStatus(ctx, message)
+	default:
+		return nil
+	}
+}
+
+// adminTasks runs long admin tasks apart from the update which started them: the context of
+// an update is cancelled once the queue is drained at shutdown, a task gets a grace period first.
+type adminTasks struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newAdminTasks(logger *logrus.Logger) *adminTasks {
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "logger", logger))
+	return &adminTasks{ctx: ctx, cancel: cancel}
+}
+
+// stop waits for running tasks for grace, then cancels them and waits for them to wind up.
+func (t *adminTasks) stop(grace time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		t.cancel()
+		select {
+		case <-done:
+		case <-time.After(shutdownTimeout):
+		}
+	}
+	t.cancel()
+}
+
+// runAdminTask runs a long admin task in the background, a panic in it is only logged.
+func (tb *telegramBot) runAdminTask(name string, task func(ctx context.Context)) {
+	tb.tasks.wg.Add(1)
+	go func() {
+		defer tb.tasks.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				tb.errors.Panics.Add(1)
+				tb.logger.WithField("stack", string(debug.Stack())).Errorf("panic in %s: %v", name, r)
+			}
+		}()
+		task(tb.tasks.ctx)
+	}()
+}
+
+// notifyTaskDone sends the result of an admin task, the task may have been cancelled by shutdown.
+func (tb *telegramBot) notifyTaskDone(chatID int64, text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_, err := tb.notify(ctx, tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+func (tb *telegramBot) handleReload(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	if tb.importer == nil {
+		return tb.reply(ctx, chatID, loc.T("admin.reload_unavailable"))
+	}
+	if tb.importer.Running() {
+		return tb.reply(ctx, chatID, loc.T("admin.reload_running"))
+	}
+	if err := tb.reply(ctx, chatID, loc.T("admin.reload_started")); err != nil {
+		return err
+	}
+
+	tb.runAdminTask("reload", func(ctx context.Context) {
+		imp, err := tb.importer.Import(ctx, true)
+		text := loc.T("admin.reload_done") + "\n\n" + importText(loc, imp)
+		switch {
+		case errors.Is(err, ErrImportRunning):
+			text = loc.T("admin.reload_running")
+		case err != nil:
+			tb.logger.WithError(err).Error("schedule import failed")
+		}
+		if err := tb.notifyTaskDone(chatID, text); err != nil {
+			tb.logger.WithError(err).Error("cannot send import result")
+		}
+	})
+	return nil
+}
+
+func importText(loc i18n.Localizer, imp service.ScheduleImport) string {
+	text := loc.T("admin.import",
+		imp.StartedAt.In(service.MoscowLocation).Format("2006-01-02 15:04"),
+		imp.FinishedAt.Sub(imp.StartedAt).Round(time.Second),
+		imp.Imported, imp.Files, imp.QuarantinedFiles, imp.QuarantinedEvents)
+	if imp.Error != nil {
+		text += "\n" + loc.T("admin.import_error", *imp.Error)
+	}
+	return text
+}
+
+func (tb *telegramBot) handleImportStatus(ctx context.Context, message *tgbotapi.Message) error {
+	loc := localizerFrom(ctx)
+	imp, err := tb.srvc.LastImport(ctx)
+	if errors.Is(err, service.ErrorNotFound) {
+		return tb.reply(ctx, message.Chat.ID, loc.T("admin.no_import"))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot get last import: %w", err)
+	}
+
+	text := importText(loc, imp)
+	if tb.importer != nil && tb.importer.Running() {
+		text += "\n\n" + loc.T("admin.reload_running")
+	}
+	return tb.reply(ctx, message.Chat.ID, text)
+}
+
+func (tb *telegramBot) handleStats(ctx context.Context, message *tgbotapi.Message) error {
+	loc := localizerFrom(ctx)
+	stats, err := tb.srvc.Stats(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot get stats: %w", err)
+	}
+
+	text := loc.T("admin.users", stats.Users, stats.NewUsers, service.StatsDays)
+
+	text += "\n\n" + loc.T("admin.queries", service.StatsDays)
+	days := make([]time.Time, 0, service.StatsDays)
+	byDay := make(map[time.Time][]service.QueryCount)
+	for _, q := range stats.Queries {
+		if _, ok := byDay[q.Day]; !ok {
+			days = append(days, q.Day)
+		}
+		byDay[q.Day] = append(byDay[q.Day], q)
+	}
+	if len(days) == 0 {
+		text += "\n" + loc.T("admin.no_queries")
+	}
+	for _, day := range days {
+		counts := byDay[day]
+		sort.Slice(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+		total, parts := 0, make([]string, 0, len(counts))
+		for _, q := range counts {
+			total += q.Count
+			parts = append(parts, fmt.Sprintf("%s %d", q.Command, q.Count))
+		}
+		text += fmt.Sprintf("\n%s: %d (%s)", day.Format("02.01"), total, strings.Join(parts, ", "))
+	}
+
+	text += "\n\n"
+	if stats.LastImport == nil {
+		text += loc.T("admin.no_import")
+	} else {
+		text += loc.T("admin.last_import", stats.LastImport.FinishedAt.In(service.MoscowLocation).Format("2006-01-02 15:04"))
+	}
+
+	queue := tb.SendQueue()
+	text += "\n\n" + loc.T("admin.errors",
+		tb.errors.User.Load(), tb.errors.Transient.Load(), tb.errors.Panics.Load(), tb.errors.Other.Load())
+	text += "\n" + loc.T("admin.send_queue", queue.Interactive, queue.Notifications, queue.Sent, queue.RateLimited)
+
+	return tb.reply(ctx, message.Chat.ID, text)
+}
+
+func (tb *telegramBot) handleBroadcast(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	text := strings.TrimSpace(message.CommandArguments())
+	if text == "" {
+		return tb.reply(ctx, chatID, loc.T("admin.broadcast_usage"))
+	}
+
+	users, err := tb.srvc.ListUsers(ctx, &service.UserFilters{})
+	if err != nil && !errors.Is(err, service.ErrorNotFound) {
+		return fmt.Errorf("cannot list users: %w", err)
+	}
+	if err := tb.reply(ctx, chatID, loc.T("admin.broadcast_started", len(users))); err != nil {
+		return err
+	}
+
+	tb.runAdminTask("broadcast", func(ctx context.Context) {
+		delivered := tb.broadcast(ctx, users, text)
+		report := loc.T("admin.broadcast_done", delivered, len(users))
+		if err := tb.notifyTaskDone(chatID, report); err != nil {
+			tb.logger.WithError(err).Error("cannot send broadcast result")
+		}
+	})
+	return nil
+}
+
+// broadcast sends text to every user with notification priority and returns how many got it,
+// users who blocked the bot are only logged.
+func (tb *telegramBot) broadcast(ctx context.Context, users []service.User, text string) int {
+	var (
+		delivered atomic.Int64
+		wg        sync.WaitGroup
+		senders   = make(chan struct{}, broadcastSenders)
+	)
+	for _, u := range users {
+		chatID, err := strconv.ParseInt(u.TelegramID, 10, 64)
+		if err != nil {
+			tb.logger.WithError(err).Error("invalid telegram id")
+			continue
+		}
+
+		select {
+		case senders <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return int(delivered.Load())
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-senders
+				wg.Done()
+			}()
+			if _, err := tb.notify(ctx, tgbotapi.NewMessage(chatID, text)); err != nil {
+				tb.logger.WithError(err).WithField("chat", chatID).Warning("cannot send broadcast")
+				return
+			}
+			delivered.Add(1)
+		}()
+	}
+	wg.Wait()
+	return int(delivered.Load())
+}
+
+// countQueries counts commands and inline queries per day for /stats, a failure is only logged.
+func (tb *telegramBot) countQueries(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) error {
+		command := ""
+		switch {
+		case update.Message != nil && update.Message.IsCommand():
+			command = update.Message.Command()
+			if !countedCommands[command] {
+				command = "other"
+			}
+		case update.InlineQuery != nil:
+			command = "inline"
+		}
+		if command != "" {
+			if err := tb.srvc.CountQuery(ctx, command); err != nil {
+				tb.logger.WithError(err).Error("cannot count query")
+			}
+		}
+		return next(ctx, update)
+	}
+}
@@ -2,257 +2,326 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
-	"sync"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/sirupsen/logrus"
 
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
 	"github.com/AlexisOMG/bmstu-free-rooms/service"
 )
 
 type Bot interface {
-	Listen(ctx context.Context, srvc *service.Service)
+	// Listen serves updates until ctx is done, it fails only if updates cannot be received at all.
+	Listen(ctx context.Context, srvc *service.Service) error
+	Errors() *ErrorStats
+	SendQueue() SendQueueStats
 }
 
-func NewBot(token string) Bot {
+// NewBot creates a bot receiving updates by long polling, or by webhook if conf sets it up; conf may be nil.
+// importer serves /reload, it may be nil if the bot has no schedule directory.
+func NewBot(token string, calendar *service.Calendar, conf *TelegramConfig, importer *ScheduleImporter) Bot {
 	return &telegramBot{
-		token: token,
+		token:    token,
+		calendar: calendar,
+		conf:     conf,
+		importer: importer,
+		users:    newUserCache(),
 	}
 }
 
 type telegramBot struct {
-	token string
+	token    string
+	calendar *service.Calendar
+	conf     *TelegramConfig
+	importer *ScheduleImporter
+
+	api    *tgbotapi.BotAPI
+	srvc   *service.Service
+	logger *logrus.Logger
+	// core runs the free room dialogue
+	core *conversation.Core
+	// users caches IDs of registered users by telegram ID
+	users  *userCache
+	errors ErrorStats
+	// outbox sends everything that goes to telegram
+	outbox *sendQueue
+	// tasks are /reload and /broadcast running in the background
+	tasks *adminTasks
+	// offsets tells which updates are handled, the saved offset lets a restart continue from them
+	offsets *offsetTracker
+	stale   staleFilter
 }
 
-type queryStore struct {
-	MessageID int
-	Filter    service.EmptyAudiencesFilter
+// converse passes the message to the conversation core and sends its replies,
+// messageID is the message whose button was pressed, zero for commands.
+func (tb *telegramBot) converse(ctx context.Context, chatID int64, messageID int, from *tgbotapi.User, msg conversation.Message) error {
+	msg.ChatID = strconv.FormatInt(chatID, 10)
+	msg.Lang = localizerFrom(ctx).Lang()
+	if from != nil {
+		userID, err := tb.registerUser(ctx, from)
+		if err != nil {
+			tb.logger.WithError(err).Error("cannot register user")
+		}
+		msg.UserID = userID
+	}
+
+	replies, err := tb.core.Handle(ctx, msg)
+	if err != nil {
+		return err
+	}
+	for _, r := range replies {
+		keyboard := replyKeyboard(r.Buttons)
+		if r.Edit && messageID != 0 {
+			edit := tgbotapi.NewEditMessageText(chatID, messageID, r.Text)
+			if len(keyboard.InlineKeyboard) > 0 {
+				edit.ReplyMarkup = &keyboard
+			}
+			_, err = tb.send(ctx, edit)
+		} else {
+			send := tgbotapi.NewMessage(chatID, r.Text)
+			if len(keyboard.InlineKeyboard) > 0 {
+				send.ReplyMarkup = keyboard
+			}
+			_, err = tb.send(ctx, send)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func replyKeyboard(buttons [][]conversation.Button) tgbotapi.InlineKeyboardMarkup {
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	for _, row := range buttons {
+		tgRow := make([]tgbotapi.InlineKeyboardButton, 0, len(row))
+		for _, b := range row {
+			tgRow = append(tgRow, tgbotapi.NewInlineKeyboardButtonData(b.Text, b.Data))
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgRow)
+	}
+	return keyboard
 }
 
-func translateWeekDay(weekDay string) string {
-	switch weekDay {
-	case "Понедельник":
-		return "Monday"
-	case "Вторник":
-		return "Tuesday"
-	case "Среда":
-		return "Wednesday"
-	case "Четверг":
-		return "Thursday"
-	case "Пятница":
-		return "Friday"
-	case "Суббота":
-		return "Saturday"
+func (tb *telegramBot) handleMessage(ctx context.Context, message *tgbotapi.Message) error {
+	switch message.Command() {
+	case "start", "now":
+		return tb.converse(ctx, message.Chat.ID, 0, message.From, conversation.Message{
+			Command: message.Command(),
+			Args:    message.CommandArguments(),
+		})
+	case "room":
+		return tb.handleRoom(ctx, message)
+	case "group":
+		return tb.handleGroup(ctx, message)
+	case "teacher":
+		return tb.handleTeacher(ctx, message)
+	case "settings":
+		return tb.handleSettings(ctx, message)
+	case "fav":
+		return tb.handleFavorite(ctx, message, true)
+	case "unfav":
+		return tb.handleFavorite(ctx, message, false)
+	case "favs":
+		return tb.handleFavorites(ctx, message)
+	case "claim":
+		return tb.handleClaim(ctx, message)
+	case "release":
+		return tb.handleRelease(ctx, message)
+	case "report":
+		return tb.handleReport(ctx, message)
+	case "reload", "stats", "broadcast", "import_status":
+		return tb.handleAdminCommand(ctx, message)
 	default:
-		return ""
+		tb.logger.WithField("unknown msg", message).Warning()
+		return nil
 	}
 }
 
-func (tb *telegramBot) Listen(ctx context.Context, srvc *service.Service) {
-	logger := ctx.Value("logger").(*logrus.Logger)
+func (tb *telegramBot) handleCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	loc := localizerFrom(ctx)
+	if clq.Message == nil {
+		// buttons of inline results come without the message
+		return conversation.NewUserError(loc.T("error.stale_button"), fmt.Errorf("callback %q without message", clq.Data))
+	}
 
-	queries := make(map[int64]queryStore)
+	switch {
+	case tb.core.Handles(conversation.Message{Data: clq.Data}):
+		return tb.converse(ctx, clq.Message.Chat.ID, clq.Message.MessageID, clq.From, conversation.Message{Data: clq.Data})
+	case strings.HasPrefix(clq.Data, roomCallbackPrefix):
+		return tb.handleRoomCallback(ctx, clq)
+	case strings.HasPrefix(clq.Data, groupCallbackPrefix):
+		return tb.handleGroupCallback(ctx, clq)
+	case strings.HasPrefix(clq.Data, teacherCallbackPrefix):
+		return tb.handleTeacherCallback(ctx, clq)
+	case strings.HasPrefix(clq.Data, settingsCallbackPrefix):
+		return tb.handleSettingsCallback(ctx, clq)
+	case strings.HasPrefix(clq.Data, favoriteCallbackPrefix):
+		return tb.handleFavoriteCallback(ctx, clq)
+	case strings.HasPrefix(clq.Data, alertCallbackPrefix):
+		return tb.handleAlertCallback(ctx, clq)
+	default:
+		// buttons of the flow sent before callback data got prefixes
+		return conversation.NewUserError(loc.T("error.stale_button"), fmt.Errorf("unknown callback %q", clq.Data))
+	}
+}
+
+func (tb *telegramBot) handleUpdate(ctx context.Context, update tgbotapi.Update) error {
+	tb.logger.WithField("update id", update.UpdateID).Debug("received update")
+	switch {
+	case update.Message != nil:
+		return tb.handleMessage(ctx, update.Message)
+	case update.CallbackQuery != nil:
+		return tb.handleCallback(ctx, update.CallbackQuery)
+	case update.InlineQuery != nil:
+		return tb.handleInlineQuery(ctx, update.InlineQuery)
+	default:
+		tb.logger.WithField("unknown upd", update).Warning()
+		return nil
+	}
+}
+
+func (tb *telegramBot) Errors() *ErrorStats {
+	return &tb.errors
+}
+
+func (tb *telegramBot) SendQueue() SendQueueStats {
+	if tb.outbox == nil {
+		return SendQueueStats{}
+	}
+	return tb.outbox.stats()
+}
+
+// pipeline wraps handleUpdate in the middlewares, the first one is the outermost. A panic is recovered
+// right inside reportErrors, so it is reported like any error, and once more outside of everything,
+// so not even a panic in the middlewares takes the worker down.
+func (tb *telegramBot) pipeline() updateHandler {
+	return chain(tb.handleUpdate,
+		recoverPanics, tb.trackOffset, tb.reportErrors, recoverPanics, tb.dropStale, tb.localize, tb.countQueries)
+}
+
+func (tb *telegramBot) Listen(ctx context.Context, srvc *service.Service) error {
+	logger := ctx.Value("logger").(*logrus.Logger)
 
-	bot, err := tgbotapi.NewBotAPI(tb.token)
+	endpoint := tgbotapi.APIEndpoint
+	if tb.conf != nil && tb.conf.APIEndpoint != nil {
+		endpoint = *tb.conf.APIEndpoint
+	}
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(tb.token, endpoint)
 	if err != nil {
-		logger.WithError(err).Fatal("cannot connect to bot")
+		return fmt.Errorf("cannot connect to bot: %w", err)
 	}
 
 	// bot.Debug = true
 
-	u := tgbotapi.NewUpdate(583350088)
-	u.Timeout = 60
-
-	updates := bot.GetUpdatesChan(u)
-
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-
-	go func(ctx context.Context, w *sync.WaitGroup, srvc *service.Service, updates tgbotapi.UpdatesChannel) {
-		defer wg.Done()
-		for {
-			select {
-			case update := <-updates:
-				fmt.Println("UPDATE ID: ", update.UpdateID)
-				if update.Message != nil {
-					if update.Message.Text != "" && update.Message.Text == "/start" {
-						msg := tgbotapi.NewMessage(update.Message.Chat.ID, "День недели")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						keyboard.InlineKeyboard = [][]tgbotapi.InlineKeyboardButton{
-							// {tgbotapi.NewInlineKeyboardButtonData("Sunday", "Sunday")},
-							{tgbotapi.NewInlineKeyboardButtonData("Понедельник", "Понедельник")},
-							{tgbotapi.NewInlineKeyboardButtonData("Вторник", "Вторник")},
-							{tgbotapi.NewInlineKeyboardButtonData("Среда", "Среда")},
-							{tgbotapi.NewInlineKeyboardButtonData("Четверг", "Четверг")},
-							{tgbotapi.NewInlineKeyboardButtonData("Пятница", "Пятница")},
-							{tgbotapi.NewInlineKeyboardButtonData("Суббота", "Суббота")},
-						}
-						msg.ReplyMarkup = keyboard
-						cm, err := bot.Send(msg)
-						if err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						queries[update.Message.Chat.ID] = queryStore{
-							MessageID: cm.MessageID,
-						}
-					} else {
-						logger.WithField("unknown msg", update.Message).Warning()
-					}
-				} else if update.CallbackQuery != nil {
-					clq := update.CallbackQuery
-					switch clq.Message.Text {
-					case "День недели":
-						query := queries[clq.Message.Chat.ID]
-						query.Filter.WeekDay = translateWeekDay(clq.Data)
-						query.MessageID = clq.Message.MessageID
-						queries[clq.Message.Chat.ID] = query
-
-						// msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Числитель или Знаменатель")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						keyboard.InlineKeyboard = [][]tgbotapi.InlineKeyboardButton{
-							{tgbotapi.NewInlineKeyboardButtonData("ЧС", "ЧС")},
-							{tgbotapi.NewInlineKeyboardButtonData("ЗН", "ЗН")},
-						}
-						// msg.ReplyMarkup = keyboard
-						msg := tgbotapi.NewEditMessageTextAndMarkup(clq.Message.Chat.ID, query.MessageID, "Числитель или Знаменатель", keyboard)
-						if _, err := bot.Send(msg); err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						fmt.Println(queries)
-					case "Числитель или Знаменатель":
-						query := queries[clq.Message.Chat.ID]
-						query.Filter.WeekType = clq.Data
-						query.MessageID = clq.Message.MessageID
-						queries[clq.Message.Chat.ID] = query
-
-						// msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Корпус")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						keyboard.InlineKeyboard = [][]tgbotapi.InlineKeyboardButton{
-							{tgbotapi.NewInlineKeyboardButtonData("ГЗ", "ГЗ")},
-							{tgbotapi.NewInlineKeyboardButtonData("УЛК", "УЛК")},
-						}
-						// msg.ReplyMarkup = keyboard
-						msg := tgbotapi.NewEditMessageTextAndMarkup(clq.Message.Chat.ID, query.MessageID, "Корпус", keyboard)
-						if _, err := bot.Send(msg); err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						fmt.Println(queries)
-					case "Корпус":
-						query := queries[clq.Message.Chat.ID]
-						query.Filter.Building = clq.Data
-						query.MessageID = clq.Message.MessageID
-						queries[clq.Message.Chat.ID] = query
-
-						// msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Этаж")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						if clq.Data == "ГЗ" {
-							for i := 1; i <= 5; i++ {
-								keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
-									tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(i), strconv.Itoa(i)),
-								})
-							}
-						} else if clq.Data == "УЛК" {
-							for i := 1; i <= 11; i++ {
-								keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
-									tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(i), strconv.Itoa(i)),
-								})
-							}
-						}
-						// msg.ReplyMarkup = keyboard
-						msg := tgbotapi.NewEditMessageTextAndMarkup(clq.Message.Chat.ID, query.MessageID, "Этаж", keyboard)
-						if _, err := bot.Send(msg); err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						fmt.Println(queries)
-					case "Этаж":
-						query := queries[clq.Message.Chat.ID]
-						floor, err := strconv.Atoi(clq.Data)
-						if err != nil {
-							logger.WithError(err).Fatal("cannot convert floor")
-						}
-						query.Filter.Floor = floor
-						query.MessageID = clq.Message.MessageID
-						queries[clq.Message.Chat.ID] = query
-
-						// msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Пара")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						for i := 1; i <= 7; i++ {
-							keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
-								tgbotapi.NewInlineKeyboardButtonData(strconv.Itoa(i), strconv.Itoa(i)),
-							})
-						}
-						// msg.ReplyMarkup = keyboard
-						msg := tgbotapi.NewEditMessageTextAndMarkup(clq.Message.Chat.ID, query.MessageID, "Пара", keyboard)
-						if _, err := bot.Send(msg); err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						fmt.Println(queries)
-					case "Пара":
-						query := queries[clq.Message.Chat.ID]
-						period, err := strconv.Atoi(clq.Data)
-						if err != nil {
-							logger.WithError(err).Fatal("cannot convert period")
-						}
-						query.Filter.Period = period
-						auds, err := srvc.ListEmptyAudiences(ctx, &query.Filter)
-						if err != nil {
-							logger.WithError(err).Fatal("cannot list empty audiences")
-							msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Что-то пошло не так:(\nПопробуй нажать /start")
-							if _, err := bot.Send(msg); err != nil {
-								logger.WithError(err).Fatal("cannot send msg to bot")
-							}
-						}
-						if len(auds) == 0 {
-							msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Нет свободных аудиторий")
-							if _, err := bot.Send(msg); err != nil {
-								logger.WithError(err).Fatal("cannot send msg to bot")
-							}
-						} else {
-							resp := ""
-							for _, aud := range auds {
-								resp += aud.Number
-								if aud.Suffix != nil {
-									resp += *aud.Suffix
-								}
-								resp += " "
-							}
-							msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "Свободные аудитории: "+resp)
-							if _, err := bot.Send(msg); err != nil {
-								logger.WithError(err).Fatal("cannot send msg to bot")
-							}
-						}
-						delete(queries, clq.Message.Chat.ID)
-						msg := tgbotapi.NewMessage(clq.Message.Chat.ID, "День недели")
-						keyboard := tgbotapi.InlineKeyboardMarkup{}
-						keyboard.InlineKeyboard = [][]tgbotapi.InlineKeyboardButton{
-							// {tgbotapi.NewInlineKeyboardButtonData("Sunday", "Sunday")},
-							{tgbotapi.NewInlineKeyboardButtonData("Понедельник", "Понедельник")},
-							{tgbotapi.NewInlineKeyboardButtonData("Вторник", "Вторник")},
-							{tgbotapi.NewInlineKeyboardButtonData("Среда", "Среда")},
-							{tgbotapi.NewInlineKeyboardButtonData("Четверг", "Четверг")},
-							{tgbotapi.NewInlineKeyboardButtonData("Пятница", "Пятница")},
-							{tgbotapi.NewInlineKeyboardButtonData("Суббота", "Суббота")},
-						}
-						msg.ReplyMarkup = keyboard
-						cm, err := bot.Send(msg)
-						if err != nil {
-							logger.WithError(err).Fatal("cannot send msg to bot")
-						}
-						queries[clq.Message.Chat.ID] = queryStore{
-							MessageID: cm.MessageID,
-						}
-					default:
-						logger.WithField("unknown query", clq).Warning()
-					}
-				} else {
-					logger.WithField("unknown upd", update).Warning()
-				}
-			case <-ctx.Done():
-				return
+	tb.api = bot
+	tb.srvc = srvc
+	tb.logger = logger
+	opts := conversation.Options{}
+	if tb.conf != nil {
+		opts = tb.conf.Conversation
+	}
+	tb.core = conversation.NewCore(srvc, tb.calendar, logger, opts)
+	tb.tasks = newAdminTasks(logger)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	offset, err := tb.loadOffset(ctx)
+	if err != nil {
+		return err
+	}
+	backlog, err := tb.fetchBacklog(offset.UpdateID + 1)
+	if err != nil {
+		return fmt.Errorf("cannot receive updates: %w", err)
+	}
+	tb.offsets = newOffsetTracker(offset.UpdateID)
+	tb.stale = staleFilter{maxAge: defaultMaxUpdateAge, backlogLast: offset.UpdateID, downtime: time.Since(offset.SavedAt)}
+	if tb.conf != nil && tb.conf.MaxUpdateAge > 0 {
+		tb.stale.maxAge = tb.conf.MaxUpdateAge
+	}
+	next := offset.UpdateID + 1
+	if len(backlog) > 0 {
+		tb.stale.backlogLast = backlog[len(backlog)-1].UpdateID
+		next = tb.stale.backlogLast + 1
+	}
+	logger.WithField("offset", offset.UpdateID).WithField("backlog", len(backlog)).
+		WithField("downtime", tb.stale.downtime.Round(time.Second)).Info("resume updates")
+
+	var updates tgbotapi.UpdatesChannel
+	if tb.conf != nil && tb.conf.Webhook != nil {
+		updates, err = tb.webhookUpdates(ctx, tb.conf.Webhook, cancel)
+		if err != nil {
+			return fmt.Errorf("cannot receive updates: %w", err)
+		}
+	} else {
+		updates = tb.pollingUpdates(next)
+	}
+
+	// queued updates are still handled after ctx is done, workCtx cuts them off if draining takes too long
+	workCtx, stopWork := context.WithCancel(context.Background())
+	defer stopWork()
+
+	tb.outbox = newSendQueue(bot.Request)
+	go tb.outbox.run(workCtx)
+
+	go tb.runFreeAlerts(ctx)
+
+	go tb.saveOffsets(ctx)
+
+	go tb.deleteExpiredClaims(ctx)
+
+	workers, queueSize := 0, 0
+	if tb.conf != nil {
+		workers, queueSize = tb.conf.Workers, tb.conf.QueueSize
+	}
+	d := newDispatcher(workCtx, tb.pipeline(), tb.logger, workers, queueSize)
+	accept := func(update tgbotapi.Update) bool {
+		tb.offsets.start(update.UpdateID)
+		if !d.dispatch(ctx, update) {
+			tb.offsets.abandon(update.UpdateID)
+			return false
+		}
+		return true
+	}
+
+	for _, update := range backlog {
+		if !accept(update) {
+			break
+		}
+	}
+
+receive:
+	for {
+		select {
+		case update := <-updates:
+			if !accept(update) {
+				break receive
 			}
+		case <-ctx.Done():
+			break receive
 		}
-	}(ctx, wg, srvc, updates)
-	wg.Wait()
+	}
+
+	if tb.conf == nil || tb.conf.Webhook == nil {
+		tb.api.StopReceivingUpdates()
+	}
+	drainTimer := time.AfterFunc(drainTimeout, stopWork)
+	d.stop()
+	drainTimer.Stop()
+	tb.tasks.stop(adminTaskGracePeriod)
+
+	saveCtx, cancelSave := context.WithTimeout(context.Background(), shutdownTimeout)
+	tb.saveOffset(saveCtx)
+	cancelSave()
+
+	if err := context.Cause(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// claimsCleanupInterval is how often expired claims are deleted, they no longer count anyway
+const claimsCleanupInterval = time.Hour
+
+// handleClaim marks a room free by timetable as taken till the end of the current or upcoming period.
+func (tb *telegramBot) handleClaim(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	aud, ok, err := tb.findAudience(ctx, chatID, message.CommandArguments(), loc.T("claim.usage"))
+	if err != nil || !ok {
+		return err
+	}
+
+	userID, err := tb.registerUser(ctx, message.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+
+	slot := tb.calendar.CurrentSlot(time.Now())
+	statuses, err := tb.srvc.AudienceStatuses(ctx, []service.Audience{aud}, slot.WeekType, slot.WeekDay, slot.Period)
+	if err != nil {
+		return fmt.Errorf("cannot get audience statuses: %w", err)
+	}
+	if !statuses[0].Free() {
+		return tb.reply(ctx, chatID, loc.T("claim.busy", conversation.AudienceName(aud)))
+	}
+
+	err = tb.srvc.ClaimAudience(ctx, userID, aud.ID, slot.End)
+	var validationErr *service.ValidationError
+	switch {
+	case errors.Is(err, service.ErrorClaimLimit):
+		return tb.replyClaimLimit(ctx, chatID, userID, aud)
+	case errors.Is(err, service.ErrorClaimExpired):
+		return tb.reply(ctx, chatID, loc.T("claim.expired", conversation.AudienceName(aud)))
+	case errors.Is(err, service.ErrorClaimTooLong):
+		return tb.reply(ctx, chatID, loc.T("claim.too_long", conversation.AudienceName(aud), int(service.MaxClaimDuration.Hours())))
+	case errors.As(err, &validationErr):
+		return tb.reply(ctx, chatID, loc.T("claim.rejected", conversation.AudienceName(aud)))
+	case err != nil:
+		return fmt.Errorf("cannot claim audience: %w", err)
+	}
+	return tb.reply(ctx, chatID, loc.T("claim.done",
+		conversation.AudienceName(aud), slot.End.Format("15:04"), conversation.AudienceName(aud)))
+}
+
+// replyClaimLimit names the rooms the user already holds, so one of them can be released.
+func (tb *telegramBot) replyClaimLimit(ctx context.Context, chatID int64, userID string, aud service.Audience) error {
+	claims, err := tb.srvc.ListActiveClaims(ctx, userID, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot list claims: %w", err)
+	}
+	held := make([]string, 0, len(claims))
+	for _, c := range claims {
+		a, err := tb.srvc.GetAudience(ctx, c.AudienceID)
+		if err != nil {
+			return fmt.Errorf("cannot get audience: %w", err)
+		}
+		held = append(held, conversation.AudienceName(a))
+	}
+	if len(held) == 0 {
+		// the claims have expired since
+		return tb.reply(ctx, chatID, localizerFrom(ctx).T("claim.rejected", conversation.AudienceName(aud)))
+	}
+	return tb.reply(ctx, chatID, localizerFrom(ctx).T("claim.limit",
+		conversation.AudienceName(aud), service.MaxActiveClaims, strings.Join(held, ", "), held[0]))
+}
+
+func (tb *telegramBot) handleRelease(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	aud, ok, err := tb.findAudience(ctx, chatID, message.CommandArguments(), loc.T("release.usage"))
+	if err != nil || !ok {
+		return err
+	}
+
+	userID, err := tb.registerUser(ctx, message.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+	if err := tb.srvc.ReleaseClaim(ctx, userID, aud.ID); err != nil {
+		return fmt.Errorf("cannot release claim: %w", err)
+	}
+	return tb.reply(ctx, chatID, loc.T("release.done", conversation.AudienceName(aud)))
+}
+
+// deleteExpiredClaims deletes expired claims every claimsCleanupInterval until ctx is done.
+func (tb *telegramBot) deleteExpiredClaims(ctx context.Context) {
+	ticker := time.NewTicker(claimsCleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			deleted, err := tb.srvc.DeleteExpiredClaims(ctx, time.Now())
+			if err != nil {
+				tb.logger.WithError(err).Error("cannot delete expired claims")
+				continue
+			}
+			tb.logger.WithField("deleted", deleted).Debug("deleted expired claims")
+		case <-ctx.Done():
+			return
+		}
+	}
+}
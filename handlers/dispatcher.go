@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWorkers   = 8
+	defaultQueueSize = 16
+	// drainTimeout limits how long queued updates are still handled after shutdown
+	drainTimeout = 10 * time.Second
+)
+
+// dispatcher handles updates concurrently. Updates of one chat always go to the same worker,
+// so they are handled in the order they came.
+type dispatcher struct {
+	handle updateHandler
+	logger *logrus.Logger
+	queues []chan tgbotapi.Update
+	wg     sync.WaitGroup
+
+	// mu keeps dispatch from sending to queues stop has closed
+	mu      sync.RWMutex
+	stopped bool
+	// quit wakes dispatch waiting for a full queue when the dispatcher stops
+	quit chan struct{}
+}
+
+// newDispatcher starts workers which handle updates with ctx, each has its own queue of queueSize updates.
+func newDispatcher(ctx context.Context, handle updateHandler, logger *logrus.Logger, workers, queueSize int) *dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	d := &dispatcher{
+		handle: handle,
+		logger: logger,
+		queues: make([]chan tgbotapi.Update, workers),
+		quit:   make(chan struct{}),
+	}
+	for i := range d.queues {
+		d.queues[i] = make(chan tgbotapi.Update, queueSize)
+		d.wg.Add(1)
+		go d.work(ctx, d.queues[i])
+	}
+	return d
+}
+
+func (d *dispatcher) work(ctx context.Context, queue <-chan tgbotapi.Update) {
+	defer d.wg.Done()
+	for update := range queue {
+		// reportErrors reports every failure, only a panic around it gets here
+		if err := d.handle(ctx, update); err != nil {
+			d.logger.WithError(err).WithField("update id", update.UpdateID).Error("cannot handle update")
+		}
+	}
+}
+
+// updateChatID returns the chat the update belongs to, inline queries have none and are keyed by the user.
+func updateChatID(update tgbotapi.Update) int64 {
+	switch {
+	case update.Message != nil:
+		return update.Message.Chat.ID
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		return update.CallbackQuery.Message.Chat.ID
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID
+	case update.InlineQuery != nil:
+		return update.InlineQuery.From.ID
+	default:
+		return 0
+	}
+}
+
+// shard returns the queue of the chat. Group chats have negative IDs, the conversion wraps them
+// to positive ones, so a chat always lands on the same queue.
+func shard(chatID int64, queues int) int {
+	return int(uint64(chatID) % uint64(queues))
+}
+
+// dispatch queues the update, it blocks while the queue of the chat is full.
+// false means ctx is done or the dispatcher is stopped, and the update was not queued.
+func (d *dispatcher) dispatch(ctx context.Context, update tgbotapi.Update) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.stopped {
+		return false
+	}
+
+	select {
+	case d.queues[shard(updateChatID(update), len(d.queues))] <- update:
+		return true
+	case <-ctx.Done():
+		return false
+	case <-d.quit:
+		return false
+	}
+}
+
+// stop lets workers handle what is already queued and waits for them, updates dispatched
+// from then on are refused. It must be called once.
+func (d *dispatcher) stop() {
+	close(d.quit)
+	d.mu.Lock()
+	d.stopped = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.mu.Unlock()
+	d.wg.Wait()
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+func chatUpdate(updateID int, chatID int64) tgbotapi.Update {
+	return tgbotapi.Update{UpdateID: updateID, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: chatID}}}
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func TestDispatcherKeepsChatOrder(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		handled = make(map[int64][]int)
+	)
+	handle := func(ctx context.Context, update tgbotapi.Update) error {
+		// later updates of other chats overtake slow ones, never of the same chat
+		if update.UpdateID%3 == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		chatID := updateChatID(update)
+		handled[chatID] = append(handled[chatID], update.UpdateID)
+		return nil
+	}
+	d := newDispatcher(context.Background(), handle, quietLogger(), 4, 2)
+
+	chats := []int64{7, 8, -100123456789, -42, 1 << 40}
+	for i := 0; i < 100; i++ {
+		if !d.dispatch(context.Background(), chatUpdate(i, chats[i%len(chats)])) {
+			t.Fatalf("update %d is not dispatched", i)
+		}
+	}
+	d.stop()
+
+	for _, chatID := range chats {
+		ids := handled[chatID]
+		if len(ids) != 100/len(chats) {
+			t.Errorf("chat %d: %d updates handled, want %d", chatID, len(ids), 100/len(chats))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Errorf("chat %d: updates handled in order %v", chatID, ids)
+				break
+			}
+		}
+	}
+}
+
+func TestShard(t *testing.T) {
+	const workers = 8
+	for _, chatID := range []int64{0, 7, -1, -42, -100123456789, 1<<63 - 1, -1 << 63} {
+		got := shard(chatID, workers)
+		if got < 0 || got >= workers {
+			t.Errorf("shard(%d) = %d, out of %d queues", chatID, got, workers)
+		}
+		if again := shard(chatID, workers); again != got {
+			t.Errorf("shard(%d) = %d, then %d", chatID, got, again)
+		}
+	}
+
+	// a message and a button press in one group go to one queue
+	group := int64(-100123456789)
+	message := chatUpdate(1, group)
+	press := tgbotapi.Update{UpdateID: 2, CallbackQuery: &tgbotapi.CallbackQuery{
+		From:    &tgbotapi.User{ID: 5},
+		Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: group}},
+	}}
+	if a, b := shard(updateChatID(message), workers), shard(updateChatID(press), workers); a != b {
+		t.Errorf("message goes to queue %d, button press to %d", a, b)
+	}
+}
+
+func TestDispatchFullQueue(t *testing.T) {
+	release := make(chan struct{})
+	handle := func(ctx context.Context, update tgbotapi.Update) error {
+		<-release
+		return nil
+	}
+	d := newDispatcher(context.Background(), handle, quietLogger(), 1, 1)
+	defer d.stop()
+	defer close(release)
+
+	fillQueue(t, d)
+	if dispatchWithin(d, chatUpdate(3, 7), 20*time.Millisecond) {
+		t.Error("an update is queued to a full queue")
+	}
+}
+
+func TestDispatchAfterStop(t *testing.T) {
+	d := newDispatcher(context.Background(), func(ctx context.Context, update tgbotapi.Update) error {
+		return nil
+	}, quietLogger(), 2, 1)
+	d.stop()
+
+	if d.dispatch(context.Background(), chatUpdate(1, 7)) {
+		t.Error("an update is dispatched after stop")
+	}
+}
+
+func TestStopWakesBlockedDispatch(t *testing.T) {
+	release := make(chan struct{})
+	handle := func(ctx context.Context, update tgbotapi.Update) error {
+		<-release
+		return nil
+	}
+	d := newDispatcher(context.Background(), handle, quietLogger(), 1, 1)
+
+	fillQueue(t, d)
+	result := make(chan bool)
+	go func() {
+		result <- d.dispatch(context.Background(), chatUpdate(3, 7))
+	}()
+
+	stopped := make(chan struct{})
+	go func() {
+		d.stop()
+		close(stopped)
+	}()
+	select {
+	case ok := <-result:
+		if ok {
+			t.Error("a blocked update is dispatched after stop")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("stop does not wake a blocked dispatch")
+	}
+	close(release)
+	<-stopped
+}
+
+// dispatchWithin dispatches the update, giving up after timeout.
+func dispatchWithin(d *dispatcher, update tgbotapi.Update, timeout time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.dispatch(ctx, update)
+}
+
+// fillQueue dispatches to chat 7 of a dispatcher with one worker and a queue of one update, whose
+// handler blocks: the worker takes the first update and the second one fills the queue.
+func fillQueue(t *testing.T, d *dispatcher) {
+	t.Helper()
+	if !d.dispatch(context.Background(), chatUpdate(1, 7)) {
+		t.Fatal("the first update is not dispatched")
+	}
+	deadline := time.Now().Add(time.Second)
+	for !dispatchWithin(d, chatUpdate(2, 7), 10*time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("the worker does not take the first update")
+		}
+	}
+}
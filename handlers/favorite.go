@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	favoriteCallbackPrefix = "fav:"
+	alertCallbackPrefix    = "alrt:"
+)
+
+// findAudience resolves the room given as a command argument, ok is false if the user has already been told what is wrong.
+func (tb *telegramBot) findAudience(ctx context.Context, chatID int64, arg, usage string) (service.Audience, bool, error) {
+	number, suffix, ok := parseRoom(arg)
+	if !ok {
+		return service.Audience{}, false, tb.reply(ctx, chatID, usage)
+	}
+
+	aud, err := tb.srvc.ListAudienceByNumber(ctx, number, suffix)
+	if errors.Is(err, service.ErrorNotFound) {
+		return service.Audience{}, false, tb.reply(ctx, chatID, localizerFrom(ctx).T("room.not_found"))
+	}
+	if err != nil {
+		return service.Audience{}, false, fmt.Errorf("cannot get audience: %w", err)
+	}
+	return aud, true, nil
+}
+
+func (tb *telegramBot) reply(ctx context.Context, chatID int64, text string) error {
+	_, err := tb.send(ctx, tgbotapi.NewMessage(chatID, text))
+	return err
+}
+
+func (tb *telegramBot) answerCallback(ctx context.Context, clq *tgbotapi.CallbackQuery, text string) error {
+	return tb.request(ctx, tgbotapi.NewCallback(clq.ID, text))
+}
+
+func (tb *telegramBot) handleFavorite(ctx context.Context, message *tgbotapi.Message, add bool) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	usage := loc.T("fav.usage")
+	if !add {
+		usage = loc.T("unfav.usage")
+	}
+	aud, ok, err := tb.findAudience(ctx, chatID, message.CommandArguments(), usage)
+	if err != nil || !ok {
+		return err
+	}
+
+	userID, err := tb.registerUser(ctx, message.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+
+	text := loc.T("fav.added", conversation.AudienceName(aud))
+	if add {
+		err = tb.srvc.AddFavorite(ctx, userID, aud.ID)
+	} else {
+		text = loc.T("fav.removed", conversation.AudienceName(aud))
+		err = tb.srvc.RemoveFavorite(ctx, userID, aud.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot update favorites: %w", err)
+	}
+	return tb.reply(ctx, chatID, text)
+}
+
+func statusLine(loc i18n.Localizer, status service.AudienceStatus) string {
+	name := html.EscapeString(conversation.AudienceName(status.Audience))
+	if status.Free() {
+		return loc.T("status.free", name, conversation.FreeUntil(loc, service.EmptyAudience{
+			Audience:       status.Audience,
+			NextBusyPeriod: status.NextBusyPeriod,
+		}))
+	}
+
+	lessons := make([]string, 0, len(status.Lessons))
+	for _, l := range status.Lessons {
+		lessons = appendUnique(lessons, l.Lesson.Name)
+	}
+	line := "⛔ " + name + " — " + html.EscapeString(strings.Join(lessons, "; "))
+	if b, ok := service.BellByPeriod(status.NextFreePeriod); ok {
+		line += loc.T("status.free_from", b.Period, b.StartString())
+	} else {
+		line += loc.T("status.busy_today")
+	}
+	return line
+}
+
+func (tb *telegramBot) handleFavorites(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	userID, err := tb.registerUser(ctx, message.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+
+	favorites, err := tb.srvc.ListFavorites(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("cannot list favorites: %w", err)
+	}
+	if len(favorites) == 0 {
+		return tb.reply(ctx, chatID, loc.T("fav.empty"))
+	}
+
+	now := time.Now()
+	slot := tb.calendar.CurrentSlot(now)
+	statuses, err := tb.srvc.AudienceStatuses(ctx, favorites, slot.WeekType, slot.WeekDay, slot.Period)
+	if err != nil {
+		return fmt.Errorf("cannot get audience statuses: %w", err)
+	}
+
+	sb := &strings.Builder{}
+	sb.WriteString(conversation.SlotDescription(loc, slot, now) + "\n")
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	for _, status := range statuses {
+		sb.WriteString(statusLine(loc, status) + "\n")
+		if !status.Free() {
+			keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData(loc.T("fav.alert_button", conversation.AudienceName(status.Audience)),
+					alertCallbackPrefix+status.Audience.ID),
+			})
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ParseMode = tgbotapi.ModeHTML
+	if len(keyboard.InlineKeyboard) > 0 {
+		msg.ReplyMarkup = keyboard
+	}
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+// handleFavoriteCallback stars the audience from its schedule message.
+func (tb *telegramBot) handleFavoriteCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	audienceID := strings.TrimPrefix(clq.Data, favoriteCallbackPrefix)
+	userID, err := tb.registerUser(ctx, clq.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+	if err := tb.srvc.AddFavorite(ctx, userID, audienceID); err != nil {
+		return fmt.Errorf("cannot add favorite: %w", err)
+	}
+	return tb.answerCallback(ctx, clq, localizerFrom(ctx).T("fav.added_button"))
+}
+
+func (tb *telegramBot) handleAlertCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	audienceID := strings.TrimPrefix(clq.Data, alertCallbackPrefix)
+	userID, err := tb.registerUser(ctx, clq.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+	if err := tb.srvc.SubscribeFreeAlert(ctx, userID, audienceID); err != nil {
+		return fmt.Errorf("cannot subscribe to free alert: %w", err)
+	}
+	return tb.answerCallback(ctx, clq, localizerFrom(ctx).T("alert.subscribed"))
+}
+
+// runFreeAlerts checks subscriptions at the end of every period and notifies users
+// whose audience is free for the next one.
+func (tb *telegramBot) runFreeAlerts(ctx context.Context) {
+	for {
+		boundary := tb.calendar.CurrentSlot(time.Now()).End
+		timer := time.NewTimer(time.Until(boundary))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		tb.safeSendFreeAlerts(ctx, boundary)
+	}
+}
+
+// safeSendFreeAlerts keeps the notifier running if one round panics.
+func (tb *telegramBot) safeSendFreeAlerts(ctx context.Context, boundary time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			tb.errors.Panics.Add(1)
+			tb.logger.WithField("stack", string(debug.Stack())).Errorf("panic while sending free alerts: %v", r)
+		}
+	}()
+	tb.sendFreeAlerts(ctx, boundary)
+}
+
+func (tb *telegramBot) sendFreeAlerts(ctx context.Context, boundary time.Time) {
+	alerts, err := tb.srvc.ListFreeAlerts(ctx)
+	if err != nil {
+		tb.logger.WithError(err).Error("cannot list free alerts")
+		return
+	}
+	if len(alerts) == 0 {
+		return
+	}
+
+	next := tb.calendar.CurrentSlot(boundary)
+	audiences := make([]service.Audience, 0, len(alerts))
+	for _, a := range alerts {
+		audiences = append(audiences, a.Audience)
+	}
+	statuses, err := tb.srvc.AudienceStatuses(ctx, audiences, next.WeekType, next.WeekDay, next.Period)
+	if err != nil {
+		tb.logger.WithError(err).Error("cannot get audience statuses")
+		return
+	}
+
+	for i, alert := range alerts {
+		if !statuses[i].Free() {
+			continue
+		}
+		if err := tb.srvc.DeleteFreeAlert(ctx, alert.ID); err != nil {
+			tb.logger.WithError(err).Error("cannot delete free alert")
+			continue
+		}
+
+		prefs, err := tb.srvc.GetPreferences(ctx, alert.UserID)
+		if err != nil {
+			tb.logger.WithError(err).Error("cannot get preferences")
+			continue
+		}
+		if !prefs.Notifications {
+			continue
+		}
+
+		chatID, err := strconv.ParseInt(alert.TelegramID, 10, 64)
+		if err != nil {
+			tb.logger.WithError(err).Error("invalid telegram id")
+			continue
+		}
+		// there is no update to take the language of the telegram client from
+		loc := userLocalizer(prefs, nil)
+		msg := tgbotapi.NewMessage(chatID, loc.T("alert.text", next.Period, next.Start.Format("15:04"), statusLine(loc, statuses[i])))
+		msg.ParseMode = tgbotapi.ModeHTML
+		if _, err := tb.notify(ctx, msg); err != nil {
+			tb.logger.WithError(err).Error("cannot send free alert")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const groupCallbackPrefix = "grp:"
+
+const (
+	groupModeToday    = "today"
+	groupModeTomorrow = "tomorrow"
+	groupModeWeek     = "week"
+)
+
+var groupModes = map[string]string{
+	"today":    groupModeToday,
+	"сегодня":  groupModeToday,
+	"tomorrow": groupModeTomorrow,
+	"завтра":   groupModeTomorrow,
+	"week":     groupModeWeek,
+	"неделя":   groupModeWeek,
+}
+
+// parseGroupArgs splits "[name] [today|tomorrow|week]", the name itself may contain spaces.
+func parseGroupArgs(args string) (string, string) {
+	fields := strings.Fields(args)
+	mode := groupModeToday
+	if len(fields) > 0 {
+		if m, ok := groupModes[strings.ToLower(fields[len(fields)-1])]; ok {
+			mode = m
+			fields = fields[:len(fields)-1]
+		}
+	}
+	return strings.Join(fields, " "), mode
+}
+
+func entryLine(e service.ScheduleEntry) string {
+	b, _ := service.BellByPeriod(e.Period)
+	line := fmt.Sprintf("%d %s–%s %s", e.Period, b.StartString(), b.EndString(), html.EscapeString(e.Lesson.Name))
+	if e.Lesson.Kind != nil && *e.Lesson.Kind != "" {
+		line += " (" + html.EscapeString(*e.Lesson.Kind) + ")"
+	}
+	line += " —"
+	if e.Lesson.TeacherName != nil && *e.Lesson.TeacherName != "" {
+		line += " " + html.EscapeString(*e.Lesson.TeacherName) + ","
+	}
+	return line + " " + html.EscapeString(conversation.AudienceName(e.Audience))
+}
+
+func writeDaySchedule(loc i18n.Localizer, sb *strings.Builder, weekDay string, entries []service.ScheduleEntry) {
+	fmt.Fprintf(sb, "\n<b>%s</b>\n", loc.WeekDay(weekDay))
+	written := false
+	for _, e := range entries {
+		if e.WeekDay != weekDay {
+			continue
+		}
+		sb.WriteString(entryLine(e) + "\n")
+		written = true
+	}
+	if !written {
+		sb.WriteString(loc.T("day.no_classes") + "\n")
+	}
+}
+
+func (tb *telegramBot) groupScheduleText(ctx context.Context, group service.Group, mode string) (string, error) {
+	day := time.Now().In(service.MoscowLocation)
+	if mode == groupModeTomorrow {
+		day = day.AddDate(0, 0, 1)
+	}
+	weekType := tb.calendar.WeekType(day)
+
+	var weekDay *string
+	if mode != groupModeWeek {
+		wd := day.Weekday().String()
+		weekDay = &wd
+	}
+
+	entries, err := tb.srvc.ListGroupSchedule(ctx, group.ID, &weekType, weekDay)
+	if err != nil {
+		return "", err
+	}
+
+	loc := localizerFrom(ctx)
+	sb := &strings.Builder{}
+	sb.WriteString(loc.T("group.title", html.EscapeString(group.Name), loc.WeekType(weekType)))
+	if weekDay != nil {
+		fmt.Fprintf(sb, ", %s\n", day.Format("02.01"))
+		writeDaySchedule(loc, sb, *weekDay, entries)
+	} else {
+		sb.WriteString("\n")
+		for _, wd := range service.WeekDays() {
+			writeDaySchedule(loc, sb, wd, entries)
+		}
+	}
+	return sb.String(), nil
+}
+
+func (tb *telegramBot) sendGroupSchedule(ctx context.Context, chatID int64, group service.Group, mode string) error {
+	text, err := tb.groupScheduleText(ctx, group, mode)
+	if err != nil {
+		return fmt.Errorf("cannot list group schedule: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+func (tb *telegramBot) handleGroup(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+
+	name, mode := parseGroupArgs(message.CommandArguments())
+	if name == "" {
+		// no group given: use the one from settings
+		prefs := tb.preferences(ctx, message.From)
+		if prefs.GroupID != nil {
+			group, err := tb.srvc.GetGroup(ctx, *prefs.GroupID)
+			if err != nil {
+				return fmt.Errorf("cannot get group: %w", err)
+			}
+			return tb.sendGroupSchedule(ctx, chatID, group, mode)
+		}
+
+		return tb.reply(ctx, chatID, loc.T("group.usage"))
+	}
+
+	groups, err := tb.srvc.SearchGroups(ctx, name)
+	if errors.Is(err, service.ErrorNotFound) {
+		return tb.reply(ctx, chatID, loc.T("group.missing"))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot search groups: %w", err)
+	}
+
+	if len(groups) == 1 {
+		return tb.sendGroupSchedule(ctx, chatID, groups[0], mode)
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	for _, g := range groups {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(g.Name, groupCallbackPrefix+g.ID+":"+mode),
+		})
+	}
+	msg := tgbotapi.NewMessage(chatID, loc.T("group.choose"))
+	msg.ReplyMarkup = keyboard
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+// handleGroupCallback shows the schedule of a group picked among several fuzzy matches.
+func (tb *telegramBot) handleGroupCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	data := strings.TrimPrefix(clq.Data, groupCallbackPrefix)
+	groupID, mode, found := strings.Cut(data, ":")
+	if !found {
+		return conversation.NewUserError(localizerFrom(ctx).T("error.stale_button"), fmt.Errorf("invalid group callback %q", clq.Data))
+	}
+
+	group, err := tb.srvc.GetGroup(ctx, groupID)
+	if err != nil {
+		return fmt.Errorf("cannot get group: %w", err)
+	}
+
+	return tb.sendGroupSchedule(ctx, clq.Message.Chat.ID, group, mode)
+}
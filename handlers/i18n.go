@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+type (
+	localizerKey     struct{}
+	localizerSlotKey struct{}
+)
+
+// localizerSlot lets a middleware outside localize, as reportErrors, speak the language of the user.
+type localizerSlot struct {
+	loc *i18n.Localizer
+}
+
+func withLocalizer(ctx context.Context, l i18n.Localizer) context.Context {
+	return context.WithValue(ctx, localizerKey{}, l)
+}
+
+// localizerFrom returns the localizer of the user the update came from, Russian if there is none.
+func localizerFrom(ctx context.Context) i18n.Localizer {
+	if l, ok := ctx.Value(localizerKey{}).(i18n.Localizer); ok {
+		return l
+	}
+	return i18n.New(service.LanguageRussian)
+}
+
+// userLocalizer prefers the language from settings, then the language of the telegram client.
+func userLocalizer(prefs service.Preferences, from *tgbotapi.User) i18n.Localizer {
+	if prefs.Language != nil {
+		return i18n.New(*prefs.Language)
+	}
+	if from != nil {
+		return i18n.New(i18n.LanguageFromCode(from.LanguageCode))
+	}
+	return i18n.New(service.LanguageRussian)
+}
+
+// localize puts the localizer of the update's author into ctx, looking up preferences registers new users.
+func (tb *telegramBot) localize(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) error {
+		from := update.SentFrom()
+		l := userLocalizer(tb.preferences(ctx, from), from)
+		if slot, ok := ctx.Value(localizerSlotKey{}).(*localizerSlot); ok {
+			slot.loc = &l
+		}
+		return next(withLocalizer(ctx, l), update)
+	}
+}
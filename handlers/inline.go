@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	// telegram accepts at most 50 results per inline answer
+	maxInlineResults = 50
+	inlineCacheTime  = 60
+)
+
+func filterDescription(loc i18n.Localizer, filter service.EmptyAudiencesFilter) string {
+	parts := make([]string, 0, 4)
+	for _, d := range filter.WeekDays {
+		parts = append(parts, loc.WeekDay(d))
+	}
+	parts = append(parts, loc.WeekType(filter.WeekType), conversation.PeriodsDescription(loc, filter.Periods))
+	return strings.Join(parts, ", ")
+}
+
+func inlineArticle(id, title, description, text string) tgbotapi.InlineQueryResultArticle {
+	article := tgbotapi.NewInlineQueryResultArticle(id, title, text)
+	article.Description = description
+	return article
+}
+
+func (tb *telegramBot) inlineResults(ctx context.Context, query string) ([]interface{}, error) {
+	loc := localizerFrom(ctx)
+	buildings, err := tb.core.KnownBuildings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter, _ := parseFreeRoomQuery(query, buildings).filter(tb.calendar, time.Now())
+	auds, err := tb.srvc.ListEmptyAudiences(ctx, &filter)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			return []interface{}{
+				inlineArticle("invalid", loc.T("inline.invalid"), loc.T("inline.example"), loc.T("inline.example_text")),
+			}, nil
+		}
+		return nil, err
+	}
+
+	description := filterDescription(loc, filter)
+	if len(auds) == 0 {
+		return []interface{}{
+			inlineArticle("empty", loc.T("free.none"), description, loc.T("free.none_for", description)),
+		}, nil
+	}
+
+	results := make([]interface{}, 0)
+	for i, group := range service.GroupByFloor(auds) {
+		if i == maxInlineResults {
+			break
+		}
+		names := make([]string, 0, len(group.Audiences))
+		lines := make([]string, 0, len(group.Audiences))
+		for _, aud := range group.Audiences {
+			names = append(names, conversation.AudienceName(aud.Audience))
+			lines = append(lines, conversation.AudienceName(aud.Audience)+" — "+conversation.FreeUntil(loc, aud)+
+				conversation.AudienceDetails(loc, aud.Audience)+conversation.ReportMark(loc, aud))
+		}
+		building := loc.Building(group.Building)
+		title := loc.T("inline.title", building, group.Floor, len(group.Audiences))
+		text := loc.T("inline.text", description, loc.T("free.floor", building, group.Floor), strings.Join(lines, "\n"))
+		results = append(results, inlineArticle(group.Building+strconv.Itoa(group.Floor), title, strings.Join(names, " "), text))
+	}
+	return results, nil
+}
+
+func (tb *telegramBot) handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) error {
+	results, err := tb.inlineResults(ctx, query.Query)
+	if err != nil {
+		return fmt.Errorf("cannot build inline results: %w", err)
+	}
+
+	return tb.request(ctx, tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		// results are in the language of the user, telegram must not share them
+		IsPersonal: true,
+	})
+}
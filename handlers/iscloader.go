@@ -2,14 +2,20 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/html"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/icsparser"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
 )
 
 type ICSDownloader interface {
@@ -22,6 +28,71 @@ func NewICSDownloader(scheduleDir string) ICSDownloader {
 	}
 }
 
+var ErrImportRunning = errors.New("schedule import is already running")
+
+// saveImportTimeout bounds recording the outcome of an import
+const saveImportTimeout = 10 * time.Second
+
+// ScheduleImporter downloads timetables of all groups and imports them, recording the outcome
+// of every run. Only one import runs at a time.
+type ScheduleImporter struct {
+	srvc       *service.Service
+	dir        string
+	downloader ICSDownloader
+	running    atomic.Bool
+}
+
+func NewScheduleImporter(srvc *service.Service, scheduleDir string) *ScheduleImporter {
+	return &ScheduleImporter{
+		srvc:       srvc,
+		dir:        scheduleDir,
+		downloader: NewICSDownloader(scheduleDir),
+	}
+}
+
+func (i *ScheduleImporter) Running() bool {
+	return i.running.Load()
+}
+
+// Import downloads the timetables if download is set and imports the schedule directory.
+// The outcome is saved even if the import fails.
+func (i *ScheduleImporter) Import(ctx context.Context, download bool) (service.ScheduleImport, error) {
+	if !i.running.CompareAndSwap(false, true) {
+		return service.ScheduleImport{}, ErrImportRunning
+	}
+	defer i.running.Store(false)
+
+	startedAt := time.Now()
+	imp, err := i.run(ctx, download)
+	imp.StartedAt = startedAt
+	imp.FinishedAt = time.Now()
+	if err != nil {
+		msg := err.Error()
+		imp.Error = &msg
+	}
+
+	// an import stopped by shutdown still deserves its record, ctx is cancelled by then
+	saveCtx, cancel := context.WithTimeout(context.Background(), saveImportTimeout)
+	defer cancel()
+	if saveErr := i.srvc.SaveImport(saveCtx, &imp); saveErr != nil {
+		return imp, errors.Join(err, fmt.Errorf("cannot save import: %w", saveErr))
+	}
+	return imp, err
+}
+
+func (i *ScheduleImporter) run(ctx context.Context, download bool) (service.ScheduleImport, error) {
+	if download {
+		if err := i.downloader.DownloadICS(ctx); err != nil {
+			return service.ScheduleImport{}, fmt.Errorf("ics loading failed: %w", err)
+		}
+	}
+	imp, err := icsparser.ProcessICSFiles(ctx, i.srvc, i.dir)
+	if err != nil {
+		return imp, fmt.Errorf("ics processing failed: %w", err)
+	}
+	return imp, nil
+}
+
 type downloader struct {
 	pathToDir string
 }
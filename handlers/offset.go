@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	// defaultMaxUpdateAge is how old an update may be before it is dropped, if the age is not configured
+	defaultMaxUpdateAge = 10 * time.Minute
+	// offsetSaveInterval is how often the offset is saved, a crash handles at most that much again
+	offsetSaveInterval = 10 * time.Second
+	// backlogBatches bounds the updates fetched at startup, 100 per batch, the rest arrive as usual
+	backlogBatches = 10
+)
+
+// offsetTracker follows updates from receiving to handling, workers finish them out of order.
+type offsetTracker struct {
+	mu       sync.Mutex
+	inFlight map[int]int
+	last     int
+}
+
+func newOffsetTracker(last int) *offsetTracker {
+	return &offsetTracker{inFlight: make(map[int]int), last: last}
+}
+
+func (t *offsetTracker) start(updateID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight[updateID]++
+}
+
+func (t *offsetTracker) done(updateID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finish(updateID)
+	if updateID > t.last {
+		t.last = updateID
+	}
+}
+
+// abandon forgets an update that was never handled, the next start receives it again.
+func (t *offsetTracker) abandon(updateID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.finish(updateID)
+}
+
+func (t *offsetTracker) finish(updateID int) {
+	if t.inFlight[updateID]--; t.inFlight[updateID] <= 0 {
+		delete(t.inFlight, updateID)
+	}
+}
+
+// processed returns the update ID up to which every received update is handled.
+func (t *offsetTracker) processed() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.inFlight) == 0 {
+		return t.last
+	}
+	oldest := t.last + 1
+	for id := range t.inFlight {
+		if id < oldest {
+			oldest = id
+		}
+	}
+	return oldest - 1
+}
+
+// staleFilter tells updates nobody waits for anymore.
+type staleFilter struct {
+	maxAge time.Duration
+	// backlogLast is the last update received while the bot was down
+	backlogLast int
+	// downtime is how long the bot was down, the whole history if it has never run
+	downtime time.Duration
+}
+
+func (f staleFilter) stale(update tgbotapi.Update, now time.Time) bool {
+	switch {
+	case update.Message != nil:
+		return now.Sub(update.Message.Time()) > f.maxAge
+	case update.CallbackQuery != nil, update.InlineQuery != nil:
+		// button presses and inline queries carry no date, judge them by the outage
+		return update.UpdateID <= f.backlogLast && f.downtime > f.maxAge
+	default:
+		return false
+	}
+}
+
+// loadOffset returns the saved offset of the bot, zero on the first run.
+func (tb *telegramBot) loadOffset(ctx context.Context) (service.UpdateOffset, error) {
+	offset, err := tb.srvc.GetUpdateOffset(ctx, tb.api.Self.ID)
+	if errors.Is(err, service.ErrorNotFound) {
+		return service.UpdateOffset{BotID: tb.api.Self.ID}, nil
+	}
+	if err != nil {
+		return service.UpdateOffset{}, fmt.Errorf("cannot get update offset: %w", err)
+	}
+	return offset, nil
+}
+
+// fetchBacklog returns updates that arrived while the bot was down. It removes a previously set
+// webhook, otherwise telegram refuses getUpdates.
+func (tb *telegramBot) fetchBacklog(offset int) ([]tgbotapi.Update, error) {
+	if _, err := tb.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		return nil, fmt.Errorf("cannot delete webhook: %w", err)
+	}
+
+	var backlog []tgbotapi.Update
+	u := tgbotapi.NewUpdate(offset)
+	u.Limit = 100
+	for i := 0; i < backlogBatches; i++ {
+		updates, err := tb.api.GetUpdates(u)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get updates: %w", err)
+		}
+		if len(updates) == 0 {
+			break
+		}
+		backlog = append(backlog, updates...)
+		u.Offset = updates[len(updates)-1].UpdateID + 1
+	}
+	return backlog, nil
+}
+
+func (tb *telegramBot) saveOffset(ctx context.Context) {
+	offset := service.UpdateOffset{BotID: tb.api.Self.ID, UpdateID: tb.offsets.processed()}
+	if err := tb.srvc.SaveUpdateOffset(ctx, &offset); err != nil {
+		tb.logger.WithError(err).Error("cannot save update offset")
+	}
+}
+
+// saveOffsets saves the offset until ctx is done, even without updates, so the next start
+// knows how long the bot was down.
+func (tb *telegramBot) saveOffsets(ctx context.Context) {
+	ticker := time.NewTicker(offsetSaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			tb.saveOffset(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// trackOffset marks the update handled, whatever the outcome, so the offset moves past it.
+func (tb *telegramBot) trackOffset(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) error {
+		defer tb.offsets.done(update.UpdateID)
+		return next(ctx, update)
+	}
+}
+
+// dropStale skips updates older than the configured age, a reply to them would only confuse.
+func (tb *telegramBot) dropStale(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) error {
+		if tb.stale.stale(update, time.Now()) {
+			tb.logger.WithField("update", update.UpdateID).Info("drop stale update")
+			return nil
+		}
+		return next(ctx, update)
+	}
+}
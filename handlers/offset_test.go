@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	type step struct {
+		op        string
+		updateID  int
+		processed int
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "in order",
+			steps: []step{
+				{"start", 11, 10}, {"start", 12, 10}, {"done", 11, 11}, {"done", 12, 12},
+			},
+		},
+		{
+			name: "out of order",
+			steps: []step{
+				{"start", 11, 10}, {"start", 12, 10}, {"start", 13, 10},
+				// 13 is done, but 11 and 12 are not, a restart must get them again
+				{"done", 13, 10}, {"done", 11, 11}, {"done", 12, 13},
+			},
+		},
+		{
+			name: "abandoned update is received again",
+			steps: []step{
+				{"start", 11, 10}, {"start", 12, 10}, {"abandon", 12, 10}, {"done", 11, 11},
+			},
+		},
+		{
+			name: "abandoned while an earlier one is in flight",
+			steps: []step{
+				{"start", 11, 10}, {"start", 12, 10}, {"abandon", 12, 10}, {"done", 11, 11},
+				{"start", 12, 11}, {"done", 12, 12},
+			},
+		},
+		{
+			name: "update received twice",
+			steps: []step{
+				{"start", 11, 10}, {"start", 11, 10}, {"done", 11, 10}, {"done", 11, 11},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newOffsetTracker(10)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "start":
+					tracker.start(s.updateID)
+				case "done":
+					tracker.done(s.updateID)
+				case "abandon":
+					tracker.abandon(s.updateID)
+				}
+				if got := tracker.processed(); got != s.processed {
+					t.Fatalf("step %d, %s %d: processed = %d, want %d", i, s.op, s.updateID, got, s.processed)
+				}
+			}
+		})
+	}
+}
+
+func TestStaleFilter(t *testing.T) {
+	now := time.Date(2026, time.September, 7, 10, 0, 0, 0, time.UTC)
+	message := func(id int, age time.Duration) tgbotapi.Update {
+		return tgbotapi.Update{UpdateID: id, Message: &tgbotapi.Message{Date: int(now.Add(-age).Unix())}}
+	}
+	callback := tgbotapi.Update{UpdateID: 100, CallbackQuery: &tgbotapi.CallbackQuery{ID: "1"}}
+	inline := tgbotapi.Update{UpdateID: 100, InlineQuery: &tgbotapi.InlineQuery{ID: "1"}}
+	after := func(u tgbotapi.Update) tgbotapi.Update {
+		u.UpdateID = 101
+		return u
+	}
+
+	long := staleFilter{maxAge: 10 * time.Minute, backlogLast: 100, downtime: time.Hour}
+	short := staleFilter{maxAge: 10 * time.Minute, backlogLast: 100, downtime: time.Minute}
+
+	tests := []struct {
+		name   string
+		filter staleFilter
+		update tgbotapi.Update
+		stale  bool
+	}{
+		{"fresh message", long, message(1, time.Minute), false},
+		{"old message", long, message(1, time.Hour), true},
+		// a message is judged by its date, not by the outage
+		{"old message after a short outage", short, message(1, time.Hour), true},
+		{"fresh message from the backlog", long, message(1, 5*time.Minute), false},
+		{"callback from the backlog of a long outage", long, callback, true},
+		{"callback from the backlog of a short outage", short, callback, false},
+		{"callback after the backlog", long, after(callback), false},
+		{"inline query from the backlog of a long outage", long, inline, true},
+		{"inline query from the backlog of a short outage", short, inline, false},
+		{"inline query after the backlog", long, after(inline), false},
+		{"other update", long, tgbotapi.Update{UpdateID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.stale(tt.update, now); got != tt.stale {
+				t.Errorf("stale() = %v, want %v", got, tt.stale)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/retry"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	retryAttempts = 3
+	retryBackoff  = 500 * time.Millisecond
+	// maxRetryAfter caps how long a request waits when telegram asks to slow down
+	maxRetryAfter = 30 * time.Second
+)
+
+// updateHandler processes one update, errors are reported to the user by the middleware.
+type updateHandler func(ctx context.Context, update tgbotapi.Update) error
+
+type middleware func(next updateHandler) updateHandler
+
+func chain(h updateHandler, middlewares ...middleware) updateHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// ErrorStats counts failed updates by kind.
+type ErrorStats struct {
+	Panics    atomic.Int64
+	Transient atomic.Int64
+	User      atomic.Int64
+	Other     atomic.Int64
+}
+
+// isTransient tells whether the failure may go away if the request is repeated.
+func isTransient(err error) bool {
+	if errors.Is(err, service.ErrorTransient) {
+		return true
+	}
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.RetryAfter > 0 || tgErr.Code == http.StatusTooManyRequests || tgErr.Code >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// rateLimited tells whether telegram asked to slow down, the send queue waits and repeats such requests itself.
+func rateLimited(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && tgErr.RetryAfter > 0
+}
+
+func isSendTransient(err error) bool {
+	return isTransient(err) && !rateLimited(err)
+}
+
+// send delivers the reply through the send queue, retrying transient failures.
+func (tb *telegramBot) send(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return tb.sendWithPriority(ctx, priorityInteractive, c)
+}
+
+// notify is send for messages the user did not ask for right now, replies go before them.
+func (tb *telegramBot) notify(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return tb.sendWithPriority(ctx, priorityNotification, c)
+}
+
+func (tb *telegramBot) sendWithPriority(ctx context.Context, priority sendPriority, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	var msg tgbotapi.Message
+	err := retry.Do(ctx, retryAttempts, retryBackoff, isSendTransient, func() error {
+		resp, err := tb.outbox.submit(ctx, priority, c)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(resp.Result, &msg)
+	})
+	if err != nil {
+		return msg, fmt.Errorf("cannot send msg to bot: %w", err)
+	}
+	return msg, nil
+}
+
+// request is send for methods which do not return a message.
+func (tb *telegramBot) request(ctx context.Context, c tgbotapi.Chattable) error {
+	err := retry.Do(ctx, retryAttempts, retryBackoff, isSendTransient, func() error {
+		_, err := tb.outbox.submit(ctx, priorityInteractive, c)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("cannot make request to bot: %w", err)
+	}
+	return nil
+}
+
+// recoverPanics turns a panic in one update into an error so the bot keeps serving others.
+func recoverPanics(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &panicError{value: r, stack: debug.Stack()}
+			}
+		}()
+		return next(ctx, update)
+	}
+}
+
+// reportErrors logs the failure, counts it and tells the user something went wrong.
+func (tb *telegramBot) reportErrors(next updateHandler) updateHandler {
+	return func(ctx context.Context, update tgbotapi.Update) error {
+		slot := &localizerSlot{}
+		err := next(context.WithValue(ctx, localizerSlotKey{}, slot), update)
+		if err == nil {
+			return nil
+		}
+
+		loc := localizerFrom(ctx)
+		if slot.loc != nil {
+			loc = *slot.loc
+		}
+		logger := tb.logger.WithError(err).WithField("update id", update.UpdateID)
+		text := loc.T("error.generic")
+		var (
+			userErr  *conversation.UserError
+			panicErr *panicError
+		)
+		switch {
+		case errors.As(err, &panicErr):
+			tb.errors.Panics.Add(1)
+			logger.WithField("stack", string(panicErr.stack)).Error("panic while handling update")
+		case errors.As(err, &userErr):
+			tb.errors.User.Add(1)
+			text = userErr.Text
+			logger.Warning("cannot handle update")
+		case isTransient(err):
+			tb.errors.Transient.Add(1)
+			logger.Error("cannot handle update, retries exhausted")
+		default:
+			tb.errors.Other.Add(1)
+			logger.Error("cannot handle update")
+		}
+
+		tb.notifyFailure(ctx, update, text)
+		return nil
+	}
+}
+
+// notifyFailure answers the update with text, failures here are only logged.
+func (tb *telegramBot) notifyFailure(ctx context.Context, update tgbotapi.Update, text string) {
+	var err error
+	switch {
+	case update.Message != nil:
+		_, err = tb.send(ctx, tgbotapi.NewMessage(update.Message.Chat.ID, text))
+	case update.CallbackQuery != nil:
+		err = tb.request(ctx, tgbotapi.NewCallback(update.CallbackQuery.ID, text))
+	case update.InlineQuery != nil:
+		err = tb.request(ctx, tgbotapi.InlineConfig{
+			InlineQueryID: update.InlineQuery.ID,
+			Results:       []interface{}{inlineArticle("error", text, "", text)},
+		})
+	}
+	if err != nil {
+		tb.logger.WithError(err).Error("cannot notify user about failure")
+	}
+}
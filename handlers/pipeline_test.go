@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// sentTexts collects texts of the messages the bot sends instead of passing them to telegram.
+type sentTexts struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (s *sentTexts) do(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	if m, ok := c.(tgbotapi.MessageConfig); ok {
+		s.mu.Lock()
+		s.texts = append(s.texts, m.Text)
+		s.mu.Unlock()
+	}
+	result, _ := json.Marshal(tgbotapi.Message{MessageID: 1, Chat: &tgbotapi.Chat{ID: 7}})
+	return &tgbotapi.APIResponse{Ok: true, Result: result}, nil
+}
+
+func (s *sentTexts) get() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.texts...)
+}
+
+func newPipelineBot(t *testing.T) (*telegramBot, *sentTexts) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	sent := &sentTexts{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	tb := &telegramBot{logger: logger, outbox: newSendQueue(sent.do)}
+	go tb.outbox.run(ctx)
+	return tb, sent
+}
+
+func TestPipelineReportsErrors(t *testing.T) {
+	generic := i18n.New(service.LanguageRussian).T("error.generic")
+
+	tests := []struct {
+		name    string
+		handler updateHandler
+		text    string
+		count   func(s *ErrorStats) int64
+	}{
+		{
+			name: "panic is recovered",
+			handler: func(ctx context.Context, update tgbotapi.Update) error {
+				var m map[string]int
+				m["boom"]++
+				return nil
+			},
+			text:  generic,
+			count: func(s *ErrorStats) int64 { return s.Panics.Load() },
+		},
+		{
+			name: "user error shows its text",
+			handler: func(ctx context.Context, update tgbotapi.Update) error {
+				return conversation.NewUserError("Кнопка устарела", errors.New("unknown callback"))
+			},
+			text:  "Кнопка устарела",
+			count: func(s *ErrorStats) int64 { return s.User.Load() },
+		},
+		{
+			name: "transient error",
+			handler: func(ctx context.Context, update tgbotapi.Update) error {
+				return service.ErrorTransient
+			},
+			text:  generic,
+			count: func(s *ErrorStats) int64 { return s.Transient.Load() },
+		},
+		{
+			name: "other error",
+			handler: func(ctx context.Context, update tgbotapi.Update) error {
+				return errors.New("broken")
+			},
+			text:  generic,
+			count: func(s *ErrorStats) int64 { return s.Other.Load() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb, sent := newPipelineBot(t)
+			calls := 0
+			h := chain(func(ctx context.Context, update tgbotapi.Update) error {
+				calls++
+				return tt.handler(ctx, update)
+			}, tb.reportErrors, recoverPanics)
+
+			update := tgbotapi.Update{UpdateID: 1, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 7}, Text: "/now"}}
+			if err := h(context.Background(), update); err != nil {
+				t.Fatalf("handler error = %v, want it reported", err)
+			}
+
+			// the update is not replayed, a storage call retries itself
+			if calls != 1 {
+				t.Errorf("handler called %d times, want once", calls)
+			}
+			if got := tt.count(&tb.errors); got != 1 {
+				t.Errorf("error count = %d, want 1", got)
+			}
+			if texts := sent.get(); len(texts) != 1 || texts[0] != tt.text {
+				t.Errorf("sent %q, want [%q]", texts, tt.text)
+			}
+		})
+	}
+}
+
+func TestPipelineSuccess(t *testing.T) {
+	tb, sent := newPipelineBot(t)
+	h := chain(func(ctx context.Context, update tgbotapi.Update) error {
+		return nil
+	}, tb.reportErrors, recoverPanics)
+
+	if err := h(context.Background(), tgbotapi.Update{UpdateID: 1, Message: &tgbotapi.Message{Chat: &tgbotapi.Chat{ID: 7}}}); err != nil {
+		t.Fatalf("handler error = %v", err)
+	}
+	if texts := sent.get(); len(texts) != 0 {
+		t.Errorf("sent %q after success", texts)
+	}
+}
+
+func TestPipelineRecoversMiddlewarePanics(t *testing.T) {
+	// the bot has no storage, so countQueries panics like a broken middleware would
+	command := tgbotapi.Update{UpdateID: 1, Message: &tgbotapi.Message{
+		Chat:     &tgbotapi.Chat{ID: 7},
+		Text:     "/now",
+		Date:     int(time.Now().Unix()),
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Length: 4}},
+	}}
+
+	t.Run("reported to the user", func(t *testing.T) {
+		tb, sent := newPipelineBot(t)
+		tb.offsets = newOffsetTracker(0)
+		tb.stale = staleFilter{maxAge: defaultMaxUpdateAge}
+
+		tb.offsets.start(command.UpdateID)
+		if err := tb.pipeline()(context.Background(), command); err != nil {
+			t.Fatalf("pipeline error = %v, want it reported", err)
+		}
+		if got := tb.errors.Panics.Load(); got != 1 {
+			t.Errorf("panics = %d, want 1", got)
+		}
+		if texts := sent.get(); len(texts) != 1 || texts[0] != i18n.New(service.LanguageRussian).T("error.generic") {
+			t.Errorf("sent %q, want the generic error", texts)
+		}
+		if got := tb.offsets.processed(); got != command.UpdateID {
+			t.Errorf("processed = %d, want %d", got, command.UpdateID)
+		}
+	})
+
+	t.Run("panic while reporting", func(t *testing.T) {
+		tb, _ := newPipelineBot(t)
+		tb.offsets = newOffsetTracker(0)
+		tb.stale = staleFilter{maxAge: defaultMaxUpdateAge}
+		// reporting the failure panics as well without the send queue
+		tb.outbox = nil
+
+		tb.offsets.start(command.UpdateID)
+		var panicErr *panicError
+		if err := tb.pipeline()(context.Background(), command); !errors.As(err, &panicErr) {
+			t.Fatalf("pipeline error = %v, want the recovered panic", err)
+		}
+		if got := tb.offsets.processed(); got != command.UpdateID {
+			t.Errorf("processed = %d, want %d", got, command.UpdateID)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var (
+	weekDayWords = map[string]string{
+		"пн":          "Monday",
+		"понедельник": "Monday",
+		"вт":          "Tuesday",
+		"вторник":     "Tuesday",
+		"ср":          "Wednesday",
+		"среда":       "Wednesday",
+		"среду":       "Wednesday",
+		"чт":          "Thursday",
+		"четверг":     "Thursday",
+		"пт":          "Friday",
+		"пятница":     "Friday",
+		"пятницу":     "Friday",
+		"сб":          "Saturday",
+		"суббота":     "Saturday",
+		"субботу":     "Saturday",
+		"mon":         "Monday",
+		"monday":      "Monday",
+		"tue":         "Tuesday",
+		"tuesday":     "Tuesday",
+		"wed":         "Wednesday",
+		"wednesday":   "Wednesday",
+		"thu":         "Thursday",
+		"thursday":    "Thursday",
+		"fri":         "Friday",
+		"friday":      "Friday",
+		"sat":         "Saturday",
+		"saturday":    "Saturday",
+	}
+
+	weekTypeWords = map[string]string{
+		"чс":          service.WeekTypeNumerator,
+		"числитель":   service.WeekTypeNumerator,
+		"зн":          service.WeekTypeDenominator,
+		"знаменатель": service.WeekTypeDenominator,
+		"numerator":   service.WeekTypeNumerator,
+		"denominator": service.WeekTypeDenominator,
+	}
+
+	floorWords  = map[string]bool{"этаж": true, "этаже": true, "эт": true, "floor": true}
+	periodWords = map[string]bool{"пара": true, "пары": true, "пару": true, "пар": true, "period": true, "periods": true}
+
+	queryTokenReg = regexp.MustCompile(`\d+\s*[-–]\s*\d+|\d+|[\p{L}]+`)
+	rangeReg      = regexp.MustCompile(`^(\d+)\s*[-–]\s*(\d+)$`)
+)
+
+// freeRoomQuery is a free-text request like "улк 3 этаж 4 пара" or "ulk floor 3 period 4".
+type freeRoomQuery struct {
+	Buildings   []string
+	Floors      []int
+	Periods     []int
+	MinCapacity int
+	Kinds       []string
+	Equipment   []string
+	WeekDay     string
+	WeekType    string
+	Tomorrow    bool
+	Now         bool
+}
+
+// parseNumbers reads a number or a range of periods, a range with a bound out of Bells is dropped.
+func parseNumbers(token string) []int {
+	if m := rangeReg.FindStringSubmatch(token); m != nil {
+		from, fromErr := strconv.Atoi(m[1])
+		to, toErr := strconv.Atoi(m[2])
+		if fromErr != nil || toErr != nil {
+			return nil
+		}
+		if from > to {
+			from, to = to, from
+		}
+		if from < 1 || to > len(service.Bells) {
+			return nil
+		}
+		return service.PeriodRange(from, to)
+	}
+	n, err := strconv.Atoi(token)
+	if err != nil {
+		return nil
+	}
+	return []int{n}
+}
+
+// parseFreeRoomQuery is tolerant: unknown words are skipped, numbers are bound to
+// the adjacent "этаж", "пара" or "человек" keyword, whichever side it stands on.
+// buildings are the names a query can mention besides the latin aliases.
+func parseFreeRoomQuery(text string, buildings []string) freeRoomQuery {
+	res := freeRoomQuery{}
+	tokens := queryTokenReg.FindAllString(strings.ToLower(text), -1)
+
+	var pending []int
+	// keyword waits for the number after it, as in "этаж 3"
+	keyword := ""
+	bind := func(word string, numbers []int) {
+		switch {
+		case floorWords[word]:
+			res.Floors = append(res.Floors, numbers...)
+		case conversation.IsCapacityWord(word):
+			res.MinCapacity = numbers[0]
+		default:
+			res.Periods = append(res.Periods, numbers...)
+		}
+	}
+
+	for _, token := range tokens {
+		if numbers := parseNumbers(token); numbers != nil {
+			if keyword != "" {
+				bind(keyword, numbers)
+				keyword = ""
+				continue
+			}
+			pending = numbers
+			continue
+		}
+		if floorWords[token] || periodWords[token] || conversation.IsCapacityWord(token) {
+			if pending != nil {
+				bind(token, pending)
+				pending = nil
+			} else {
+				keyword = token
+			}
+			continue
+		}
+		pending = nil
+		keyword = ""
+
+		if b, ok := conversation.ParseBuilding(token, buildings); ok {
+			res.Buildings = append(res.Buildings, b)
+			continue
+		}
+		if k, ok := conversation.ParseAudienceKind(token); ok {
+			res.Kinds = append(res.Kinds, k)
+			continue
+		}
+		if e, ok := conversation.ParseEquipment(token); ok {
+			res.Equipment = append(res.Equipment, e)
+			continue
+		}
+		if d, ok := weekDayWords[token]; ok {
+			res.WeekDay = d
+			continue
+		}
+		if wt, ok := weekTypeWords[token]; ok {
+			res.WeekType = wt
+			continue
+		}
+		switch token {
+		case "сейчас", "now":
+			res.Now = true
+		case "сегодня", "today":
+			res.Tomorrow = false
+		case "завтра", "tomorrow":
+			res.Tomorrow = true
+		}
+	}
+
+	return res
+}
+
+// filter resolves omitted parts of the query against the calendar: the current day,
+// week parity and period are used by default.
+func (q freeRoomQuery) filter(calendar *service.Calendar, now time.Time) (service.EmptyAudiencesFilter, service.Slot) {
+	from := now.In(service.MoscowLocation)
+	if q.Tomorrow {
+		from = time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, service.MoscowLocation)
+	}
+	slot := calendar.CurrentSlot(from)
+	if q.WeekDay != "" && !q.Now {
+		slot = weekDaySlot(calendar, from, q.WeekDay)
+	}
+
+	filter := service.EmptyAudiencesFilter{
+		Buildings:   q.Buildings,
+		Floors:      q.Floors,
+		WeekType:    slot.WeekType,
+		WeekDays:    []string{slot.WeekDay},
+		Periods:     []int{slot.Period},
+		MinCapacity: q.MinCapacity,
+		Kinds:       q.Kinds,
+		Equipment:   q.Equipment,
+	}
+	if q.WeekType != "" {
+		filter.WeekType = q.WeekType
+	}
+	if len(q.Periods) > 0 && !q.Now {
+		filter.Periods = q.Periods
+	}
+	return filter, slot
+}
+
+// weekDaySlot returns the current or the first period of the nearest weekDay at or after from.
+// Once the periods of the day are over CurrentSlot moves on to the next study day, so the same
+// week day a week later is taken instead, with its own parity.
+func weekDaySlot(calendar *service.Calendar, from time.Time, weekDay string) service.Slot {
+	day := from
+	for i := 0; i < 7 && day.Weekday().String() != weekDay; i++ {
+		day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, service.MoscowLocation)
+	}
+	slot := calendar.CurrentSlot(day)
+	if slot.WeekDay != weekDay {
+		slot = calendar.CurrentSlot(time.Date(day.Year(), day.Month(), day.Day()+7, 0, 0, 0, 0, service.MoscowLocation))
+	}
+	return slot
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+func TestParseFreeRoomQuery(t *testing.T) {
+	tests := []struct {
+		text string
+		want freeRoomQuery
+	}{
+		{"", freeRoomQuery{}},
+		{"ulk 3 этаж 4 пара", freeRoomQuery{Buildings: []string{"УЛК"}, Floors: []int{3}, Periods: []int{4}}},
+		{"ulk floor 3 period 4", freeRoomQuery{Buildings: []string{"УЛК"}, Floors: []int{3}, Periods: []int{4}}},
+		{"gz пары 2-4", freeRoomQuery{Buildings: []string{"ГЗ"}, Periods: []int{2, 3, 4}}},
+		{"4–2 пары", freeRoomQuery{Periods: []int{2, 3, 4}}},
+		{"на 30 человек с проектором", freeRoomQuery{MinCapacity: 30, Equipment: []string{service.EquipmentProjector}}},
+		{"лаба в среду зн", freeRoomQuery{Kinds: []string{service.AudienceKindLab}, WeekDay: "Wednesday", WeekType: service.WeekTypeDenominator}},
+		{"завтра 1 пара", freeRoomQuery{Periods: []int{1}, Tomorrow: true}},
+		{"завтра сегодня", freeRoomQuery{}},
+		{"сейчас", freeRoomQuery{Now: true}},
+		// a number without its keyword is dropped
+		{"3 ulk", freeRoomQuery{Buildings: []string{"УЛК"}}},
+		// buildings come from the database and keep their spelling
+		{"улк 2 этаж", freeRoomQuery{Buildings: []string{"УЛК"}, Floors: []int{2}}},
+		{"см 5 этаж", freeRoomQuery{Buildings: []string{"СМ"}, Floors: []int{5}}},
+		// ranges beyond the bells are dropped instead of being spelled out
+		{"1-3000000000 пара", freeRoomQuery{}},
+		{"пары 0-3", freeRoomQuery{}},
+		{"5-9 пар", freeRoomQuery{}},
+		{"1-99999999999999999999 пара", freeRoomQuery{}},
+		{"1-7 пары", freeRoomQuery{Periods: []int{1, 2, 3, 4, 5, 6, 7}}},
+	}
+	buildings := []string{"ГЗ", "УЛК", "СМ"}
+	for _, tt := range tests {
+		if got := parseFreeRoomQuery(tt.text, buildings); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseFreeRoomQuery(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFreeRoomQueryFilter(t *testing.T) {
+	// the week of 2026-08-31 is ЧС, the next one is ЗН
+	calendar := service.NewCalendar(time.Date(2026, time.August, 31, 0, 0, 0, 0, service.MoscowLocation))
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.September, day, hour, min, 0, 0, service.MoscowLocation)
+	}
+
+	type want struct {
+		weekType string
+		weekDay  string
+		periods  []int
+	}
+	tests := []struct {
+		name  string
+		query string
+		now   time.Time
+		want  want
+	}{
+		{"current period", "", at(7, 9, 0), want{service.WeekTypeDenominator, "Monday", []int{1}}},
+		{"next period", "", at(7, 10, 10), want{service.WeekTypeDenominator, "Monday", []int{2}}},
+		{"after the last period", "", at(7, 21, 0), want{service.WeekTypeDenominator, "Tuesday", []int{1}}},
+		{"saturday evening", "", at(12, 21, 0), want{service.WeekTypeNumerator, "Monday", []int{1}}},
+		{"tomorrow", "завтра", at(7, 15, 0), want{service.WeekTypeDenominator, "Tuesday", []int{1}}},
+		{"tomorrow from saturday", "завтра", at(12, 12, 0), want{service.WeekTypeNumerator, "Monday", []int{1}}},
+		{"later week day", "пт", at(7, 15, 0), want{service.WeekTypeDenominator, "Friday", []int{1}}},
+		{"week day of the next week", "пн", at(9, 15, 0), want{service.WeekTypeNumerator, "Monday", []int{1}}},
+		{"today during periods", "пн", at(7, 15, 0), want{service.WeekTypeDenominator, "Monday", []int{4}}},
+		{"today after the last period", "пн", at(7, 21, 0), want{service.WeekTypeNumerator, "Monday", []int{1}}},
+		{"saturday after the last period", "сб", at(12, 21, 0), want{service.WeekTypeNumerator, "Saturday", []int{1}}},
+		{"explicit periods", "ср 3-4 пары", at(7, 9, 0), want{service.WeekTypeDenominator, "Wednesday", []int{3, 4}}},
+		{"explicit week type", "ср чс", at(7, 9, 0), want{service.WeekTypeNumerator, "Wednesday", []int{1}}},
+		{"now ignores the day and periods", "сейчас пт 5 пара", at(7, 9, 0), want{service.WeekTypeDenominator, "Monday", []int{1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, _ := parseFreeRoomQuery(tt.query, nil).filter(calendar, tt.now)
+			got := want{filter.WeekType, "", filter.Periods}
+			if len(filter.WeekDays) == 1 {
+				got.weekDay = filter.WeekDays[0]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter of %q at %v = %+v, want %+v", tt.query, tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var reportStateWords = map[string]string{
+	"занята":   service.ReportStateOccupied,
+	"занято":   service.ReportStateOccupied,
+	"occupied": service.ReportStateOccupied,
+	"закрыта":  service.ReportStateLocked,
+	"закрыто":  service.ReportStateLocked,
+	"locked":   service.ReportStateLocked,
+	"свободна": service.ReportStateFree,
+	"свободно": service.ReportStateFree,
+	"free":     service.ReportStateFree,
+}
+
+// parseReportArgs splits "<room> <state>", the room itself may contain a space before the suffix.
+func parseReportArgs(args string) (string, string, bool) {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return "", "", false
+	}
+	state, ok := reportStateWords[strings.ToLower(fields[len(fields)-1])]
+	if !ok {
+		return "", "", false
+	}
+	return strings.Join(fields[:len(fields)-1], " "), state, true
+}
+
+// handleReport stores what the user sees in the audience during the current period.
+func (tb *telegramBot) handleReport(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	room, state, ok := parseReportArgs(message.CommandArguments())
+	if !ok {
+		return tb.reply(ctx, chatID, loc.T("report.usage"))
+	}
+	aud, ok, err := tb.findAudience(ctx, chatID, room, loc.T("report.usage"))
+	if err != nil || !ok {
+		return err
+	}
+
+	userID, err := tb.registerUser(ctx, message.From)
+	if err != nil {
+		return fmt.Errorf("cannot register user: %w", err)
+	}
+
+	slot := tb.calendar.CurrentSlot(time.Now())
+	err = tb.srvc.ReportRoom(ctx, &service.RoomReport{
+		UserID:     userID,
+		AudienceID: aud.ID,
+		WeekType:   slot.WeekType,
+		WeekDay:    slot.WeekDay,
+		Period:     slot.Period,
+		State:      state,
+	})
+	var validationErr *service.ValidationError
+	if errors.As(err, &validationErr) {
+		return conversation.NewUserError(loc.T("report.usage"), err)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot save report: %w", err)
+	}
+	return tb.reply(ctx, chatID, loc.T("report.thanks", conversation.AudienceName(aud), slot.Period))
+}
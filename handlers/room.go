@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/icsparser"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const roomCallbackPrefix = "room:"
+
+// parseRoom turns user input like "395ю" or "395 Ю" into audience number and suffix.
+func parseRoom(arg string) (string, *string, bool) {
+	room := strings.ToLower(strings.Join(strings.Fields(arg), ""))
+	if room == "" {
+		return "", nil, false
+	}
+	number, suffix := icsparser.SplitLocation(room)
+	if number == "" {
+		return "", nil, false
+	}
+	return number, suffix, true
+}
+
+type slotKey struct {
+	WeekDay string
+	Period  int
+}
+
+// slotLessons merges schedule rows of one slot: the same lesson is stored once per group.
+type slotLessons struct {
+	Lessons []string
+	Groups  []string
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, l := range list {
+			if l == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func groupBySlot(entries []service.ScheduleEntry) map[slotKey]*slotLessons {
+	res := make(map[slotKey]*slotLessons)
+	for _, e := range entries {
+		key := slotKey{WeekDay: e.WeekDay, Period: e.Period}
+		if _, ok := res[key]; !ok {
+			res[key] = &slotLessons{}
+		}
+		res[key].Lessons = appendUnique(res[key].Lessons, e.Lesson.Name)
+		res[key].Groups = appendUnique(res[key].Groups, e.Groups...)
+	}
+	for _, sl := range res {
+		sort.Strings(sl.Groups)
+	}
+	return res
+}
+
+func roomSchedule(loc i18n.Localizer, aud service.Audience, weekType string, entries []service.ScheduleEntry) string {
+	slots := groupBySlot(entries)
+
+	sb := &strings.Builder{}
+	sb.WriteString(loc.T("room.title", html.EscapeString(conversation.AudienceName(aud)), loc.Building(aud.Building), loc.WeekType(weekType)))
+
+	sb.WriteString("<pre>   ")
+	for _, b := range service.Bells {
+		fmt.Fprintf(sb, " %d", b.Period)
+	}
+	sb.WriteString("\n")
+	for _, day := range service.WeekDays() {
+		sb.WriteString(loc.WeekDayShort(day) + " ")
+		for _, b := range service.Bells {
+			if _, ok := slots[slotKey{WeekDay: day, Period: b.Period}]; ok {
+				sb.WriteString(" ■")
+			} else {
+				sb.WriteString(" □")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("</pre>\n")
+
+	if len(slots) == 0 {
+		sb.WriteString(loc.T("room.free_week"))
+		return sb.String()
+	}
+
+	for _, day := range service.WeekDays() {
+		dayWritten := false
+		for _, b := range service.Bells {
+			sl, ok := slots[slotKey{WeekDay: day, Period: b.Period}]
+			if !ok {
+				continue
+			}
+			if !dayWritten {
+				fmt.Fprintf(sb, "\n<b>%s</b>\n", loc.WeekDayShort(day))
+				dayWritten = true
+			}
+			fmt.Fprintf(sb, "%d %s — %s", b.Period, b.StartString(), html.EscapeString(strings.Join(sl.Lessons, "; ")))
+			if len(sl.Groups) > 0 {
+				fmt.Fprintf(sb, " (%s)", html.EscapeString(strings.Join(sl.Groups, ", ")))
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+func roomKeyboard(loc i18n.Localizer, audienceID, weekType string) tgbotapi.InlineKeyboardMarkup {
+	buttons := make([]tgbotapi.InlineKeyboardButton, 0, 2)
+	for _, wt := range []string{service.WeekTypeNumerator, service.WeekTypeDenominator} {
+		text := loc.WeekType(wt)
+		if wt == weekType {
+			text = "• " + text + " •"
+		}
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(text, roomCallbackPrefix+audienceID+":"+wt))
+	}
+	return tgbotapi.NewInlineKeyboardMarkup(buttons, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(loc.T("room.add_favorite"), favoriteCallbackPrefix+audienceID),
+	))
+}
+
+func (tb *telegramBot) roomScheduleText(ctx context.Context, aud service.Audience, weekType string) (string, error) {
+	entries, err := tb.srvc.ListScheduleEntries(ctx, &service.ScheduleEntryFilters{
+		AudienceIDs: []string{aud.ID},
+		WeekType:    &weekType,
+	})
+	if err != nil {
+		return "", err
+	}
+	return roomSchedule(localizerFrom(ctx), aud, weekType, entries), nil
+}
+
+func (tb *telegramBot) handleRoom(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	aud, ok, err := tb.findAudience(ctx, chatID, message.CommandArguments(), loc.T("room.usage"))
+	if err != nil || !ok {
+		return err
+	}
+
+	weekType := tb.calendar.WeekType(time.Now())
+	text, err := tb.roomScheduleText(ctx, aud, weekType)
+	if err != nil {
+		return fmt.Errorf("cannot list audience schedule: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	msg.ReplyMarkup = roomKeyboard(loc, aud.ID, weekType)
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+// handleRoomCallback switches the week type of an already sent room schedule.
+func (tb *telegramBot) handleRoomCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	loc := localizerFrom(ctx)
+	data := strings.TrimPrefix(clq.Data, roomCallbackPrefix)
+	audienceID, weekType, found := strings.Cut(data, ":")
+	if !found {
+		return conversation.NewUserError(loc.T("error.stale_button"), fmt.Errorf("invalid room callback %q", clq.Data))
+	}
+
+	aud, err := tb.srvc.GetAudience(ctx, audienceID)
+	if err != nil {
+		return fmt.Errorf("cannot get audience: %w", err)
+	}
+
+	text, err := tb.roomScheduleText(ctx, aud, weekType)
+	if err != nil {
+		return fmt.Errorf("cannot list audience schedule: %w", err)
+	}
+
+	msg := tgbotapi.NewEditMessageTextAndMarkup(clq.Message.Chat.ID, clq.Message.MessageID, text, roomKeyboard(loc, aud.ID, weekType))
+	msg.ParseMode = tgbotapi.ModeHTML
+	_, err = tb.send(ctx, msg)
+	return err
+}
@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Telegram allows about 30 messages per second overall and about one per second in a chat.
+const (
+	globalSendRate  = 30
+	globalSendBurst = 30
+	chatSendRate    = 1
+	chatSendBurst   = 3
+	// maxSendsInFlight is how many requests wait for telegram at once
+	maxSendsInFlight = 8
+	// chatBucketsPruneInterval is how often buckets of quiet chats are forgotten
+	chatBucketsPruneInterval = time.Minute
+)
+
+var errSendQueueStopped = errors.New("send queue stopped")
+
+type sendPriority int
+
+const (
+	// priorityInteractive is for replies to the user, they go first
+	priorityInteractive sendPriority = iota
+	// priorityNotification is for messages the user did not ask for right now
+	priorityNotification
+	priorities
+)
+
+// tokenBucket allows rate requests per second on average and up to burst at once.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if now.After(b.last) {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// wait returns how long until a token is available, zero if there is one already.
+func (b *tokenBucket) wait(now time.Time) time.Duration {
+	b.refill(now)
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) take(now time.Time) {
+	b.refill(now)
+	b.tokens--
+}
+
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+type sendResult struct {
+	resp *tgbotapi.APIResponse
+	err  error
+}
+
+type sendJob struct {
+	ctx      context.Context
+	priority sendPriority
+	// chatID is zero for requests which do not post to a chat, e.g. callback answers
+	chatID   int64
+	c        tgbotapi.Chattable
+	attempts int
+	done     chan sendResult
+}
+
+// SendQueueStats shows how loaded the outgoing queue is.
+type SendQueueStats struct {
+	Interactive   int
+	Notifications int
+	Sent          int64
+	// RateLimited counts requests telegram answered with 429
+	RateLimited int64
+}
+
+// sendQueue passes requests to telegram within its rate limits. Requests of one chat
+// are sent one by one in order, replies overtake notifications, and after a 429
+// nothing is sent until retry_after passes.
+type sendQueue struct {
+	do func(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+
+	mu          sync.Mutex
+	queues      [priorities][]*sendJob
+	global      *tokenBucket
+	chats       map[int64]*tokenBucket
+	busy        map[int64]bool
+	pausedUntil time.Time
+	lastPrune   time.Time
+	stopped     bool
+
+	wake  chan struct{}
+	slots chan struct{}
+
+	sent        atomic.Int64
+	rateLimited atomic.Int64
+}
+
+func newSendQueue(do func(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)) *sendQueue {
+	now := time.Now()
+	return &sendQueue{
+		do:        do,
+		global:    newTokenBucket(globalSendRate, globalSendBurst, now),
+		chats:     make(map[int64]*tokenBucket),
+		busy:      make(map[int64]bool),
+		lastPrune: now,
+		wake:      make(chan struct{}, 1),
+		slots:     make(chan struct{}, maxSendsInFlight),
+	}
+}
+
+// chattableChatID returns the chat the request posts to, zero if it is not limited by chat.
+func chattableChatID(c tgbotapi.Chattable) int64 {
+	switch c := c.(type) {
+	case tgbotapi.MessageConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return c.ChatID
+	default:
+		return 0
+	}
+}
+
+func (q *sendQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// submit queues the request and waits for telegram to answer it.
+func (q *sendQueue) submit(ctx context.Context, priority sendPriority, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	job := &sendJob{
+		ctx:      ctx,
+		priority: priority,
+		chatID:   chattableChatID(c),
+		c:        c,
+		done:     make(chan sendResult, 1),
+	}
+
+	q.mu.Lock()
+	if q.stopped {
+		q.mu.Unlock()
+		return nil, errSendQueueStopped
+	}
+	q.queues[priority] = append(q.queues[priority], job)
+	q.mu.Unlock()
+	q.notify()
+
+	select {
+	case res := <-job.done:
+		return res.resp, res.err
+	case <-ctx.Done():
+		// next drops the job
+		return nil, ctx.Err()
+	}
+}
+
+// next takes the first job allowed to be sent now, otherwise it returns how long to wait;
+// zero wait means waiting until something is submitted or finished.
+func (q *sendQueue) next(now time.Time) (*sendJob, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if now.Sub(q.lastPrune) > chatBucketsPruneInterval {
+		for chatID, b := range q.chats {
+			if !q.busy[chatID] && b.full(now) {
+				delete(q.chats, chatID)
+			}
+		}
+		q.lastPrune = now
+	}
+
+	if now.Before(q.pausedUntil) {
+		return nil, q.pausedUntil.Sub(now)
+	}
+	if wait := q.global.wait(now); wait > 0 {
+		return nil, wait
+	}
+
+	var minWait time.Duration
+	for p := range q.queues {
+		queue := q.queues[p]
+		for i := 0; i < len(queue); i++ {
+			job := queue[i]
+			if job.ctx.Err() != nil {
+				queue = append(queue[:i], queue[i+1:]...)
+				i--
+				continue
+			}
+
+			if job.chatID != 0 {
+				if q.busy[job.chatID] {
+					continue
+				}
+				b, ok := q.chats[job.chatID]
+				if !ok {
+					b = newTokenBucket(chatSendRate, chatSendBurst, now)
+					q.chats[job.chatID] = b
+				}
+				if wait := b.wait(now); wait > 0 {
+					if minWait == 0 || wait < minWait {
+						minWait = wait
+					}
+					continue
+				}
+				b.take(now)
+				q.busy[job.chatID] = true
+			}
+
+			q.global.take(now)
+			q.queues[p] = append(queue[:i], queue[i+1:]...)
+			return job, 0
+		}
+		q.queues[p] = queue
+	}
+	return nil, minWait
+}
+
+func (q *sendQueue) execute(job *sendJob) {
+	defer func() { <-q.slots }()
+	defer q.notify()
+
+	resp, err := q.do(job.c)
+
+	q.mu.Lock()
+	delete(q.busy, job.chatID)
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		q.rateLimited.Add(1)
+		job.attempts++
+		if job.attempts < retryAttempts && !q.stopped {
+			delay := time.Duration(tgErr.RetryAfter) * time.Second
+			if delay > maxRetryAfter {
+				delay = maxRetryAfter
+			}
+			// telegram does not tell whether the chat or the whole bot is limited
+			q.pausedUntil = time.Now().Add(delay)
+			q.queues[job.priority] = append([]*sendJob{job}, q.queues[job.priority]...)
+			q.mu.Unlock()
+			return
+		}
+	}
+	q.mu.Unlock()
+
+	if err == nil {
+		q.sent.Add(1)
+	}
+	job.done <- sendResult{resp: resp, err: err}
+}
+
+// run sends queued requests until ctx is done, then fails the ones left.
+func (q *sendQueue) run(ctx context.Context) {
+	defer q.stop()
+
+	for {
+		job, wait := q.next(time.Now())
+		if job != nil {
+			select {
+			case q.slots <- struct{}{}:
+				go q.execute(job)
+				continue
+			case <-ctx.Done():
+				job.done <- sendResult{err: errSendQueueStopped}
+				return
+			}
+		}
+
+		var (
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-q.wake:
+		case <-timeout:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func (q *sendQueue) stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stopped = true
+	for p := range q.queues {
+		for _, job := range q.queues[p] {
+			job.done <- sendResult{err: errSendQueueStopped}
+		}
+		q.queues[p] = nil
+	}
+}
+
+func (q *sendQueue) stats() SendQueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return SendQueueStats{
+		Interactive:   len(q.queues[priorityInteractive]),
+		Notifications: len(q.queues[priorityNotification]),
+		Sent:          q.sent.Load(),
+		RateLimited:   q.rateLimited.Load(),
+	}
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+var queueStart = time.Date(2026, time.September, 7, 10, 0, 0, 0, time.UTC)
+
+// newTestSendQueue returns a queue whose buckets are full at queueStart, do answers every request.
+func newTestSendQueue(do func(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)) *sendQueue {
+	q := newSendQueue(do)
+	q.global = newTokenBucket(globalSendRate, globalSendBurst, queueStart)
+	q.lastPrune = queueStart
+	return q
+}
+
+// push queues a job the way submit does, without waiting for it.
+func push(q *sendQueue, ctx context.Context, priority sendPriority, chatID int64) *sendJob {
+	var c tgbotapi.Chattable = tgbotapi.NewCallback("1", "ok")
+	if chatID != 0 {
+		c = tgbotapi.NewMessage(chatID, "hi")
+	}
+	job := &sendJob{ctx: ctx, priority: priority, chatID: chattableChatID(c), c: c, done: make(chan sendResult, 1)}
+	q.queues[priority] = append(q.queues[priority], job)
+	return job
+}
+
+func TestSendQueueGlobalBurst(t *testing.T) {
+	q := newTestSendQueue(nil)
+	for i := 0; i < globalSendBurst+1; i++ {
+		push(q, context.Background(), priorityInteractive, 0)
+	}
+
+	for i := 0; i < globalSendBurst; i++ {
+		if job, wait := q.next(queueStart); job == nil {
+			t.Fatalf("job %d is held back for %v within the burst", i, wait)
+		}
+	}
+	job, wait := q.next(queueStart)
+	if job != nil {
+		t.Fatal("a job past the burst is sent at once")
+	}
+	if want := time.Second / globalSendRate; wait != want {
+		t.Errorf("wait = %v, want %v", wait, want)
+	}
+	if job, _ := q.next(queueStart.Add(wait)); job == nil {
+		t.Error("the job is held back after the wait")
+	}
+}
+
+func TestSendQueueChats(t *testing.T) {
+	q := newTestSendQueue(nil)
+	first := push(q, context.Background(), priorityInteractive, 7)
+	second := push(q, context.Background(), priorityInteractive, 7)
+	other := push(q, context.Background(), priorityInteractive, 8)
+
+	if job, _ := q.next(queueStart); job != first {
+		t.Fatal("the first job is not sent first")
+	}
+	// chat 7 waits for its first message, the other chat goes meanwhile
+	if job, _ := q.next(queueStart); job != other {
+		t.Fatal("a busy chat holds back another one")
+	}
+	if job, wait := q.next(queueStart); job != nil || wait != 0 {
+		t.Fatalf("next() = %v, %v while the chat is busy, want to wait for it", job, wait)
+	}
+
+	delete(q.busy, 7)
+	if job, _ := q.next(queueStart); job != second {
+		t.Fatal("the second job is not sent after the first one is done")
+	}
+
+	// the chat bucket allows chatSendBurst messages at once, then one per second
+	for i := 0; i < chatSendBurst-2; i++ {
+		delete(q.busy, 7)
+		push(q, context.Background(), priorityInteractive, 7)
+		if job, _ := q.next(queueStart); job == nil {
+			t.Fatalf("message %d of the chat burst is held back", i+3)
+		}
+	}
+	delete(q.busy, 7)
+	push(q, context.Background(), priorityInteractive, 7)
+	job, wait := q.next(queueStart)
+	if job != nil {
+		t.Fatal("a message past the chat burst is sent at once")
+	}
+	if want := time.Second / chatSendRate; wait != want {
+		t.Errorf("wait = %v, want %v", wait, want)
+	}
+}
+
+func TestSendQueuePriority(t *testing.T) {
+	q := newTestSendQueue(nil)
+	notification := push(q, context.Background(), priorityNotification, 7)
+	reply := push(q, context.Background(), priorityInteractive, 8)
+
+	if job, _ := q.next(queueStart); job != reply {
+		t.Fatal("a reply does not overtake a notification")
+	}
+	if job, _ := q.next(queueStart); job != notification {
+		t.Fatal("the notification is not sent after the reply")
+	}
+}
+
+func TestSendQueueRateLimited(t *testing.T) {
+	limited := &tgbotapi.Error{Code: 429, Message: "Too Many Requests", ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 2}}
+	q := newTestSendQueue(func(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+		return nil, limited
+	})
+	job := push(q, context.Background(), priorityNotification, 7)
+	if got, _ := q.next(queueStart); got != job {
+		t.Fatal("the job is not sent")
+	}
+	behind := push(q, context.Background(), priorityNotification, 8)
+
+	q.slots <- struct{}{}
+	before := time.Now()
+	q.execute(job)
+
+	select {
+	case res := <-job.done:
+		t.Fatalf("the job is answered with %v instead of being queued again", res.err)
+	default:
+	}
+	if queue := q.queues[priorityNotification]; len(queue) != 2 || queue[0] != job || queue[1] != behind {
+		t.Fatal("the rate limited job is not queued again in front")
+	}
+	if q.busy[7] {
+		t.Error("the chat stays busy after the request")
+	}
+	if q.pausedUntil.Before(before.Add(2 * time.Second)) {
+		t.Errorf("paused until %v, want at least retry_after from now", q.pausedUntil)
+	}
+	if got := q.rateLimited.Load(); got != 1 {
+		t.Errorf("rate limited = %d, want 1", got)
+	}
+
+	// nothing goes while paused, another chat neither
+	now := q.pausedUntil.Add(-time.Second)
+	if got, wait := q.next(now); got != nil || wait != time.Second {
+		t.Fatalf("next() while paused = %v, %v, want to wait a second", got, wait)
+	}
+	if got, _ := q.next(q.pausedUntil); got != job {
+		t.Fatal("the rate limited job is not the first one after the pause")
+	}
+}
+
+func TestSendQueueDropsCancelled(t *testing.T) {
+	q := newTestSendQueue(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	push(q, ctx, priorityInteractive, 7)
+	live := push(q, context.Background(), priorityInteractive, 8)
+
+	if job, _ := q.next(queueStart); job != live {
+		t.Fatal("the cancelled job is not skipped")
+	}
+	if n := len(q.queues[priorityInteractive]); n != 0 {
+		t.Errorf("%d jobs left in the queue, want the cancelled one dropped", n)
+	}
+	if _, ok := q.chats[7]; ok {
+		t.Error("the cancelled job took a token of its chat")
+	}
+}
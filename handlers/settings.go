@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	settingsCallbackPrefix = "set:"
+
+	settingBuilding      = "b"
+	settingFloor         = "f"
+	settingLanguage      = "l"
+	settingNotifications = "n"
+	settingGroup         = "g"
+
+	// unsetValue resets a preference to "not chosen"
+	unsetValue = "-"
+)
+
+var languageNames = map[string]string{
+	service.LanguageRussian: "Русский",
+	service.LanguageEnglish: "English",
+}
+
+// registerUser stores the telegram user on first contact and returns its ID in user_info.
+func (tb *telegramBot) registerUser(ctx context.Context, from *tgbotapi.User) (string, error) {
+	if id, ok := tb.users.get(from.ID); ok {
+		return id, nil
+	}
+
+	user := &service.User{
+		TelegramID: strconv.FormatInt(from.ID, 10),
+	}
+	if from.UserName != "" {
+		user.Username = &from.UserName
+	}
+	if from.FirstName != "" {
+		user.FirstName = &from.FirstName
+	}
+	if from.LastName != "" {
+		user.LastName = &from.LastName
+	}
+
+	id, err := tb.srvc.SaveUser(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	tb.users.set(from.ID, id)
+	return id, nil
+}
+
+// preferences returns preferences of the telegram user, the defaults are used if the user cannot be registered.
+func (tb *telegramBot) preferences(ctx context.Context, from *tgbotapi.User) service.Preferences {
+	if from == nil {
+		return service.Preferences{Notifications: true}
+	}
+	userID, err := tb.registerUser(ctx, from)
+	if err != nil {
+		tb.logger.WithError(err).Error("cannot register user")
+		return service.Preferences{Notifications: true}
+	}
+	prefs, err := tb.srvc.GetPreferences(ctx, userID)
+	if err != nil {
+		tb.logger.WithError(err).Error("cannot get preferences")
+		return service.Preferences{UserID: userID, Notifications: true}
+	}
+	return prefs
+}
+
+func (tb *telegramBot) settingsText(ctx context.Context, loc i18n.Localizer, prefs service.Preferences) string {
+	building, floor, group := loc.T("settings.unset"), loc.T("settings.unset"), loc.T("settings.unset_group")
+	language := loc.T("settings.language_auto") + " (" + languageNames[loc.Lang()] + ")"
+	if prefs.DefaultBuilding != nil {
+		building = loc.Building(*prefs.DefaultBuilding)
+	}
+	if prefs.DefaultFloor != nil {
+		floor = strconv.Itoa(*prefs.DefaultFloor)
+	}
+	if prefs.GroupID != nil {
+		g, err := tb.srvc.GetGroup(ctx, *prefs.GroupID)
+		if err != nil {
+			tb.logger.WithError(err).Error("cannot get group")
+		} else {
+			group = g.Name
+		}
+	}
+	if prefs.Language != nil {
+		language = languageNames[*prefs.Language]
+	}
+	notifications := loc.T("settings.off")
+	if prefs.Notifications {
+		notifications = loc.T("settings.on")
+	}
+
+	return loc.T("settings.text", building, floor, group, language, notifications)
+}
+
+func settingsKeyboard(loc i18n.Localizer) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.building"), settingsCallbackPrefix+settingBuilding),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.floor"), settingsCallbackPrefix+settingFloor),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.language"), settingsCallbackPrefix+settingLanguage),
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.notifications"), settingsCallbackPrefix+settingNotifications),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(loc.T("settings.reset_group"), settingsCallbackPrefix+settingGroup+":"+unsetValue),
+		),
+	)
+}
+
+// settingOptionsKeyboard lists values of one preference, buildings and floors are those having audiences.
+func (tb *telegramBot) settingOptionsKeyboard(ctx context.Context, loc i18n.Localizer, setting string, prefs service.Preferences) (tgbotapi.InlineKeyboardMarkup, error) {
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	add := func(text, value string) {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(text, settingsCallbackPrefix+setting+":"+value),
+		})
+	}
+	switch setting {
+	case settingBuilding:
+		buildings, err := tb.srvc.ListBuildings(ctx)
+		if err != nil {
+			return keyboard, fmt.Errorf("cannot list buildings: %w", err)
+		}
+		for _, b := range buildings {
+			add(loc.Building(b.Name), b.Name)
+		}
+		add(loc.T("settings.not_chosen"), unsetValue)
+	case settingFloor:
+		var buildings []string
+		if prefs.DefaultBuilding != nil {
+			buildings = []string{*prefs.DefaultBuilding}
+		}
+		floors, err := tb.srvc.ListFloors(ctx, buildings...)
+		if err != nil {
+			return keyboard, fmt.Errorf("cannot list floors: %w", err)
+		}
+		seen := make(map[int]bool)
+		numbers := make([]int, 0, len(floors))
+		for _, f := range floors {
+			if !seen[f.Floor] {
+				seen[f.Floor] = true
+				numbers = append(numbers, f.Floor)
+			}
+		}
+		sort.Ints(numbers)
+		for _, f := range numbers {
+			add(strconv.Itoa(f), strconv.Itoa(f))
+		}
+		add(loc.T("settings.not_chosen"), unsetValue)
+	case settingLanguage:
+		for _, l := range []string{service.LanguageRussian, service.LanguageEnglish} {
+			add(languageNames[l], l)
+		}
+		add(loc.T("settings.language_auto"), unsetValue)
+	}
+	return keyboard, nil
+}
+
+func (tb *telegramBot) handleSettings(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+	prefs := tb.preferences(ctx, message.From)
+
+	args := strings.Fields(message.CommandArguments())
+	if len(args) > 1 && args[0] == "group" {
+		groups, err := tb.srvc.SearchGroups(ctx, strings.Join(args[1:], " "))
+		switch {
+		case errors.Is(err, service.ErrorNotFound):
+			return tb.reply(ctx, chatID, loc.T("group.missing"))
+		case err != nil:
+			return fmt.Errorf("cannot search groups: %w", err)
+		case len(groups) > 1:
+			keyboard := tgbotapi.InlineKeyboardMarkup{}
+			for _, g := range groups {
+				keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
+					tgbotapi.NewInlineKeyboardButtonData(g.Name, settingsCallbackPrefix+settingGroup+":"+g.ID),
+				})
+			}
+			msg := tgbotapi.NewMessage(chatID, loc.T("group.choose"))
+			msg.ReplyMarkup = keyboard
+			_, err = tb.send(ctx, msg)
+			return err
+		default:
+			prefs.GroupID = &groups[0].ID
+			if err := tb.srvc.SavePreferences(ctx, &prefs); err != nil {
+				return fmt.Errorf("cannot save preferences: %w", err)
+			}
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, tb.settingsText(ctx, loc, prefs))
+	msg.ReplyMarkup = settingsKeyboard(loc)
+	_, err := tb.send(ctx, msg)
+	return err
+}
+
+// handleSettingsCallback either opens the list of values of a preference ("set:b")
+// or stores the chosen value ("set:b:ГЗ").
+func (tb *telegramBot) handleSettingsCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	chatID, messageID := clq.Message.Chat.ID, clq.Message.MessageID
+	setting, value, hasValue := strings.Cut(strings.TrimPrefix(clq.Data, settingsCallbackPrefix), ":")
+	prefs := tb.preferences(ctx, clq.From)
+	loc := localizerFrom(ctx)
+
+	if !hasValue && setting != settingNotifications {
+		keyboard, err := tb.settingOptionsKeyboard(ctx, loc, setting, prefs)
+		if err != nil {
+			return err
+		}
+		_, err = tb.send(ctx, tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard))
+		return err
+	}
+
+	switch setting {
+	case settingBuilding:
+		prefs.DefaultBuilding = nil
+		if value != unsetValue {
+			prefs.DefaultBuilding = &value
+		}
+	case settingFloor:
+		prefs.DefaultFloor = nil
+		if floor, err := strconv.Atoi(value); err == nil {
+			prefs.DefaultFloor = &floor
+		}
+	case settingLanguage:
+		prefs.Language = nil
+		if value != unsetValue {
+			prefs.Language = &value
+		}
+	case settingNotifications:
+		prefs.Notifications = !prefs.Notifications
+	case settingGroup:
+		prefs.GroupID = nil
+		if value != unsetValue {
+			prefs.GroupID = &value
+		}
+	default:
+		return conversation.NewUserError(loc.T("error.stale_button"), fmt.Errorf("unknown setting %q", clq.Data))
+	}
+
+	var validationErr *service.ValidationError
+	if err := tb.srvc.SavePreferences(ctx, &prefs); errors.As(err, &validationErr) {
+		return conversation.NewUserError(loc.T("settings.invalid"), err)
+	} else if err != nil {
+		return fmt.Errorf("cannot save preferences: %w", err)
+	}
+
+	// the language may have just changed
+	loc = userLocalizer(prefs, clq.From)
+	_, err := tb.send(ctx, tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, tb.settingsText(ctx, loc, prefs), settingsKeyboard(loc)))
+	return err
+}
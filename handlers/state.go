@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"sync"
+)
+
+// userCache maps telegram IDs to IDs of registered users. One user writes to
+// several chats, so the same ID may be looked up by different workers at once.
+type userCache struct {
+	mu    sync.RWMutex
+	users map[int64]string
+}
+
+func newUserCache() *userCache {
+	return &userCache{users: make(map[int64]string)}
+}
+
+func (c *userCache) get(telegramID int64) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	id, ok := c.users[telegramID]
+	return id, ok
+}
+
+func (c *userCache) set(telegramID int64, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[telegramID] = id
+}
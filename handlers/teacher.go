@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+	"github.com/AlexisOMG/bmstu-free-rooms/i18n"
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	teacherCallbackPrefix = "tch:"
+	// telegram rejects callback data longer than 64 bytes
+	maxCallbackDataLen = 64
+)
+
+func truncateCallbackData(data string) string {
+	for len(data) > maxCallbackDataLen {
+		_, size := utf8.DecodeLastRuneInString(data)
+		data = data[:len(data)-size]
+	}
+	return data
+}
+
+type occurrence struct {
+	Entry service.ScheduleEntry
+	Start time.Time
+	End   time.Time
+}
+
+// teacherWhereabouts finds the lesson going on at now and the next one after it.
+func (tb *telegramBot) teacherWhereabouts(entries []service.ScheduleEntry, now time.Time) (*occurrence, *occurrence) {
+	var current, next *occurrence
+	for _, e := range entries {
+		start, end, ok := tb.calendar.NextOccurrence(now, e.WeekType, e.WeekDay, e.Period)
+		if !ok {
+			continue
+		}
+		o := &occurrence{Entry: e, Start: start, End: end}
+		if !now.Before(start) {
+			current = o
+			continue
+		}
+		if next == nil || start.Before(next.Start) {
+			next = o
+		}
+	}
+	return current, next
+}
+
+func whenDescription(loc i18n.Localizer, t, now time.Time) string {
+	now = now.In(service.MoscowLocation)
+	switch {
+	case conversation.SameDay(t, now):
+		return loc.T("when.today", t.Format("15:04"))
+	case conversation.SameDay(t, now.AddDate(0, 0, 1)):
+		return loc.T("when.tomorrow", t.Format("15:04"))
+	default:
+		return loc.T("when.week_day", loc.OnWeekDay(t.Weekday().String()), t.Format("15:04"))
+	}
+}
+
+func whereaboutsText(loc i18n.Localizer, current, next *occurrence, now time.Time) string {
+	parts := make([]string, 0, 2)
+	if current != nil {
+		parts = append(parts, loc.T("teacher.now_in",
+			html.EscapeString(conversation.AudienceName(current.Entry.Audience)), current.End.Format("15:04")))
+	} else {
+		parts = append(parts, loc.T("teacher.now_free"))
+	}
+	if next != nil {
+		parts = append(parts, loc.T("teacher.next",
+			whenDescription(loc, next.Start, now), html.EscapeString(conversation.AudienceName(next.Entry.Audience))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dedupEntries drops rows which differ only by group: a lecture is stored once per group.
+func dedupEntries(entries []service.ScheduleEntry) []service.ScheduleEntry {
+	type key struct {
+		WeekType   string
+		WeekDay    string
+		Period     int
+		AudienceID string
+	}
+	seen := make(map[key]int)
+	res := make([]service.ScheduleEntry, 0, len(entries))
+	for _, e := range entries {
+		k := key{e.WeekType, e.WeekDay, e.Period, e.Audience.ID}
+		if i, ok := seen[k]; ok {
+			res[i].Groups = appendUnique(res[i].Groups, e.Groups...)
+			continue
+		}
+		seen[k] = len(res)
+		res = append(res, e)
+	}
+	return res
+}
+
+func (tb *telegramBot) teacherScheduleText(ctx context.Context, teacher string) (string, error) {
+	entries, err := tb.srvc.ListTeacherSchedule(ctx, teacher, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	entries = dedupEntries(entries)
+
+	loc := localizerFrom(ctx)
+	now := time.Now()
+	current, next := tb.teacherWhereabouts(entries, now)
+	weekType := tb.calendar.WeekType(now)
+
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "<b>%s</b>\n%s\n", html.EscapeString(teacher), whereaboutsText(loc, current, next, now))
+	sb.WriteString(loc.T("teacher.week", loc.WeekType(weekType)))
+	for _, wd := range service.WeekDays() {
+		dayWritten := false
+		for _, e := range entries {
+			if e.WeekType != weekType || e.WeekDay != wd {
+				continue
+			}
+			if !dayWritten {
+				fmt.Fprintf(sb, "\n<b>%s</b>\n", loc.WeekDay(wd))
+				dayWritten = true
+			}
+			sb.WriteString(entryLine(e))
+			if len(e.Groups) > 0 {
+				fmt.Fprintf(sb, " (%s)", html.EscapeString(strings.Join(e.Groups, ", ")))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+func (tb *telegramBot) sendTeacherSchedule(ctx context.Context, chatID int64, teacher string) error {
+	text, err := tb.teacherScheduleText(ctx, teacher)
+	if err != nil {
+		return fmt.Errorf("cannot list teacher schedule: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = tgbotapi.ModeHTML
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+func (tb *telegramBot) handleTeacher(ctx context.Context, message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	loc := localizerFrom(ctx)
+
+	name := strings.TrimSpace(message.CommandArguments())
+	if name == "" {
+		return tb.reply(ctx, chatID, loc.T("teacher.usage"))
+	}
+
+	teachers, err := tb.srvc.SearchTeachers(ctx, name)
+	if errors.Is(err, service.ErrorNotFound) {
+		return tb.reply(ctx, chatID, loc.T("teacher.missing"))
+	}
+	if err != nil {
+		return fmt.Errorf("cannot search teachers: %w", err)
+	}
+
+	if len(teachers) == 1 {
+		return tb.sendTeacherSchedule(ctx, chatID, teachers[0])
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{}
+	for _, t := range teachers {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{
+			tgbotapi.NewInlineKeyboardButtonData(t, truncateCallbackData(teacherCallbackPrefix+t)),
+		})
+	}
+	msg := tgbotapi.NewMessage(chatID, loc.T("teacher.choose"))
+	msg.ReplyMarkup = keyboard
+	_, err = tb.send(ctx, msg)
+	return err
+}
+
+// handleTeacherCallback shows the schedule of a teacher picked among several matches.
+// Long names are truncated in callback data, so the name is resolved once more.
+func (tb *telegramBot) handleTeacherCallback(ctx context.Context, clq *tgbotapi.CallbackQuery) error {
+	name := strings.TrimPrefix(clq.Data, teacherCallbackPrefix)
+
+	teachers, err := tb.srvc.SearchTeachers(ctx, name)
+	if err != nil {
+		return fmt.Errorf("cannot search teachers: %w", err)
+	}
+
+	teacher := teachers[0]
+	for _, t := range teachers {
+		if t == name {
+			teacher = t
+			break
+		}
+	}
+
+	return tb.sendTeacherSchedule(ctx, clq.Message.Chat.ID, teacher)
+}
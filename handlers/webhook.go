@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/conversation"
+)
+
+const (
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+	shutdownTimeout   = 5 * time.Second
+)
+
+type TelegramConfig struct {
+	// APIEndpoint overrides the Bot API address, e.g. a local stand-in: http://localhost:8081/bot%s/%s
+	APIEndpoint *string `yaml:"api_endpoint"`
+	// Webhook switches the bot from long polling to webhook mode
+	Webhook *WebhookConfig `yaml:"webhook"`
+	// Workers is the number of updates handled at once, 8 by default
+	Workers int `yaml:"workers"`
+	// QueueSize is how many updates wait for each worker before receiving blocks, 16 by default
+	QueueSize int `yaml:"queue_size"`
+	// Conversation tunes the free room dialogue, its fields sit right in the telegram section
+	Conversation conversation.Options `yaml:",inline"`
+	// Admins are telegram IDs allowed to use admin commands, besides users with is_admin set in user_info
+	Admins []int64 `yaml:"admins"`
+	// MaxUpdateAge drops updates older than it, e.g. button presses made during an outage, 10m by default
+	MaxUpdateAge time.Duration `yaml:"max_update_age"`
+}
+
+type WebhookConfig struct {
+	// Listen is the address of the HTTP server, e.g. ":8443"
+	Listen string `yaml:"listen"`
+	// URL is the public address telegram posts updates to, its path is served by the HTTP server
+	URL string `yaml:"url"`
+	// SecretToken is sent back by telegram in every request, requests without it are rejected;
+	// a random one is generated at start if it is not set
+	SecretToken string `yaml:"secret_token"`
+	// CertFile and KeyFile make the server use TLS
+	CertFile *string `yaml:"cert_file"`
+	KeyFile  *string `yaml:"key_file"`
+	// UploadCert sends CertFile to telegram, needed for self-signed certificates
+	UploadCert bool `yaml:"upload_cert"`
+}
+
+func (conf *WebhookConfig) tls() bool {
+	return conf.CertFile != nil && conf.KeyFile != nil
+}
+
+// newSecretToken makes a token of the characters telegram allows in secret_token.
+func newSecretToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("cannot generate secret token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (tb *telegramBot) setWebhook(conf *WebhookConfig, secret string) error {
+	params := tgbotapi.Params{}
+	params["url"] = conf.URL
+	params["secret_token"] = secret
+
+	var err error
+	if conf.UploadCert && conf.CertFile != nil {
+		_, err = tb.api.UploadFiles("setWebhook", params, []tgbotapi.RequestFile{{
+			Name: "certificate",
+			Data: tgbotapi.FilePath(*conf.CertFile),
+		}})
+	} else {
+		_, err = tb.api.MakeRequest("setWebhook", params)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot set webhook: %w", err)
+	}
+	return nil
+}
+
+// webhookHandler accepts updates posted by telegram with the secret token and feeds them into updates.
+func (tb *telegramBot) webhookHandler(ctx context.Context, secret string, updates chan<- tgbotapi.Update) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(secretTokenHeader)), []byte(secret)) != 1 {
+			tb.logger.WithField("remote addr", r.RemoteAddr).Warning("webhook request with invalid secret token")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		update, err := tb.api.HandleUpdate(r)
+		if err != nil {
+			tb.logger.WithError(err).Warning("cannot decode webhook update")
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		select {
+		case updates <- *update:
+			w.WriteHeader(http.StatusOK)
+		case <-ctx.Done():
+			// telegram redelivers the update after restart
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// webhookUpdates registers the webhook and serves it until ctx is done, fail stops the bot if the server breaks.
+func (tb *telegramBot) webhookUpdates(ctx context.Context, conf *WebhookConfig, fail context.CancelCauseFunc) (tgbotapi.UpdatesChannel, error) {
+	u, err := url.Parse(conf.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	// without a secret anyone who finds the url could post updates on behalf of any user
+	secret := conf.SecretToken
+	if secret == "" {
+		if secret, err = newSecretToken(); err != nil {
+			return nil, err
+		}
+		tb.logger.Info("webhook secret_token is not set, generated one")
+	}
+	if err := tb.setWebhook(conf, secret); err != nil {
+		return nil, err
+	}
+
+	updates := make(chan tgbotapi.Update, tb.api.Buffer)
+	mux := http.NewServeMux()
+	mux.Handle(path, tb.webhookHandler(ctx, secret, updates))
+	server := &http.Server{
+		Addr:              conf.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		var err error
+		if conf.tls() {
+			err = server.ListenAndServeTLS(*conf.CertFile, *conf.KeyFile)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fail(fmt.Errorf("webhook server failed: %w", err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			tb.logger.WithError(err).Error("cannot shutdown webhook server")
+		}
+	}()
+
+	tb.logger.WithField("addr", conf.Listen).WithField("path", path).Info("listening for webhook")
+	return updates, nil
+}
+
+// pollingUpdates long polls updates starting with offset, the webhook is already removed by fetchBacklog.
+func (tb *telegramBot) pollingUpdates(offset int) tgbotapi.UpdatesChannel {
+	u := tgbotapi.NewUpdate(offset)
+	u.Timeout = 60
+
+	return tb.api.GetUpdatesChan(u)
+}
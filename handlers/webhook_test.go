@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// botAPIStandIn answers getMe and setWebhook like the Bot API and remembers the setWebhook parameters.
+type botAPIStandIn struct {
+	mu      sync.Mutex
+	webhook map[string]string
+}
+
+func (s *botAPIStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getMe"):
+		io.WriteString(w, `{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Test","username":"test_bot"}}`)
+	case strings.HasSuffix(r.URL.Path, "/setWebhook"):
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.webhook = map[string]string{"url": r.PostForm.Get("url"), "secret_token": r.PostForm.Get("secret_token")}
+		s.mu.Unlock()
+		io.WriteString(w, `{"ok":true,"result":true}`)
+	default:
+		io.WriteString(w, `{"ok":false,"error_code":404,"description":"Not Found"}`)
+	}
+}
+
+func newTestBot(t *testing.T) (*telegramBot, *botAPIStandIn) {
+	t.Helper()
+	standIn := &botAPIStandIn{}
+	server := httptest.NewServer(standIn)
+	t.Cleanup(server.Close)
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint("123:token", server.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatalf("cannot connect to the stand-in: %v", err)
+	}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return &telegramBot{api: api, logger: logger}, standIn
+}
+
+func TestWebhookGeneratesSecretToken(t *testing.T) {
+	tb, standIn := newTestBot(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conf := &WebhookConfig{Listen: "127.0.0.1:0", URL: "https://example.com/hook"}
+	if _, err := tb.webhookUpdates(ctx, conf, func(error) {}); err != nil {
+		t.Fatalf("webhookUpdates() error = %v", err)
+	}
+
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	if standIn.webhook["url"] != conf.URL {
+		t.Errorf("setWebhook url = %q, want %q", standIn.webhook["url"], conf.URL)
+	}
+	if len(standIn.webhook["secret_token"]) != 64 {
+		t.Errorf("setWebhook secret_token = %q, want a generated one", standIn.webhook["secret_token"])
+	}
+}
+
+func TestWebhookSecretToken(t *testing.T) {
+	const secret = "s3cret"
+	body := `{"update_id":42,"message":{"message_id":1,"date":1700000000,"chat":{"id":7,"type":"private"},"text":"/now"}}`
+
+	tests := []struct {
+		name   string
+		token  *string
+		status int
+	}{
+		{"valid token", strPtr(secret), http.StatusOK},
+		{"missing token", nil, http.StatusForbidden},
+		{"wrong token", strPtr("guess"), http.StatusForbidden},
+		{"empty token", strPtr(""), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tb, _ := newTestBot(t)
+			updates := make(chan tgbotapi.Update, 1)
+			server := httptest.NewServer(tb.webhookHandler(context.Background(), secret, updates))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if tt.token != nil {
+				req.Header.Set(secretTokenHeader, *tt.token)
+			}
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			select {
+			case update := <-updates:
+				if tt.status != http.StatusOK {
+					t.Errorf("update %d accepted without the secret token", update.UpdateID)
+				} else if update.UpdateID != 42 {
+					t.Errorf("update ID = %d, want 42", update.UpdateID)
+				}
+			default:
+				if tt.status == http.StatusOK {
+					t.Error("update was not passed on")
+				}
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,447 @@
+// Package i18n holds texts of the bot in the supported languages.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+// catalog holds texts of the bot by language and key. Keys missing in a language fall back to Russian.
+var catalog = map[string]map[string]string{
+	service.LanguageRussian: {
+		"error.generic":      "Что-то пошло не так:(",
+		"error.retry_start":  "Что-то пошло не так:(\nПопробуй нажать /start",
+		"error.stale_button": "Кнопка устарела",
+		"error.bad_choice":   "Не понял выбор, попробуй /start",
+
+		"query.week_day":     "День недели",
+		"query.week_type":    "Числитель или Знаменатель",
+		"query.building":     "Корпус",
+		"query.floor":        "Этаж",
+		"query.period":       "Пара",
+		"query.last_period":  "До какой пары",
+		"query.any_building": "Любой корпус",
+		"query.any_floor":    "Любой этаж",
+		"query.only_period":  "Только %d",
+
+		"query.building_rooms":   "%s · %d ауд.",
+		"query.floor_rooms":      "%d этаж · %d ауд.",
+		"query.floor_rooms_free": "%d этаж · %d ауд., весь день свободны %d",
+
+		"periods.one":   "%d пара",
+		"periods.range": "%d–%d пары",
+		"periods.list":  "%s пары",
+
+		"free.until":         "до %s (%d пара)",
+		"free.until_day_end": "до конца дня",
+		"free.none":          "Нет свободных аудиторий",
+		"free.none_for":      "Нет свободных аудиторий: %s",
+		"free.header":        "Свободные аудитории, %s:",
+		"free.floor":         "%s, %d этаж",
+		"free.claimed":       "Уже заняты студентами:",
+		"free.claimed_line":  "%s (%s) — до %s",
+
+		"slot.in_progress": "Сейчас идёт %d пара (%s–%s, %s).\n",
+		"slot.next":        "Сейчас пар нет. Следующая, %d пара, начнётся %s в %s (%s).\n",
+		"day.today":        "сегодня",
+		"day.tomorrow":     "завтра",
+		"day.no_classes":   "Пар нет",
+		"when.today":       "в %s",
+		"when.tomorrow":    "завтра в %s",
+		"when.week_day":    "%s в %s",
+
+		"weekday.Monday":    "Понедельник",
+		"weekday.Tuesday":   "Вторник",
+		"weekday.Wednesday": "Среда",
+		"weekday.Thursday":  "Четверг",
+		"weekday.Friday":    "Пятница",
+		"weekday.Saturday":  "Суббота",
+		"weekday.Sunday":    "Воскресенье",
+
+		"weekday_short.Monday":    "Пн",
+		"weekday_short.Tuesday":   "Вт",
+		"weekday_short.Wednesday": "Ср",
+		"weekday_short.Thursday":  "Чт",
+		"weekday_short.Friday":    "Пт",
+		"weekday_short.Saturday":  "Сб",
+		"weekday_short.Sunday":    "Вс",
+
+		"on_weekday.Monday":    "в понедельник",
+		"on_weekday.Tuesday":   "во вторник",
+		"on_weekday.Wednesday": "в среду",
+		"on_weekday.Thursday":  "в четверг",
+		"on_weekday.Friday":    "в пятницу",
+		"on_weekday.Saturday":  "в субботу",
+		"on_weekday.Sunday":    "в воскресенье",
+
+		"weektype." + service.WeekTypeNumerator:   "ЧС",
+		"weektype." + service.WeekTypeDenominator: "ЗН",
+
+		"building.ГЗ":  "ГЗ",
+		"building.УЛК": "УЛК",
+
+		"kind.lecture":  "лекционная",
+		"kind.computer": "компьютерный класс",
+		"kind.lab":      "лаборатория",
+		"kind.seminar":  "семинарская",
+
+		"equipment.projector":  "проектор",
+		"equipment.whiteboard": "доска",
+		"equipment.computers":  "компьютеры",
+		"equipment.sockets":    "розетки",
+
+		"audience.capacity": "мест: %d",
+
+		"now.usage": "Не понял запрос. Пример: /now УЛК 3 или /now компьютерный на 20 человек",
+
+		"room.usage":        "Укажи аудиторию, например: /room 395ю",
+		"room.not_found":    "Аудитория не найдена",
+		"room.title":        "Аудитория <b>%s</b> (%s), %s\n",
+		"room.free_week":    "Аудитория свободна всю неделю",
+		"room.add_favorite": "☆ В избранное",
+
+		"fav.usage":        "Укажи аудиторию, например: /fav 395ю",
+		"unfav.usage":      "Укажи аудиторию, например: /unfav 395ю",
+		"fav.added":        "%s добавлена в избранное, посмотреть: /favs",
+		"fav.removed":      "%s удалена из избранного",
+		"fav.empty":        "Избранных аудиторий нет. Добавить: /fav 395ю",
+		"fav.added_button": "Добавлено в избранное",
+		"fav.alert_button": "🔔 Сообщить, когда %s освободится",
+
+		"status.free":       "✅ %s — свободна %s",
+		"status.free_from":  ", освободится к %d паре (%s)",
+		"status.busy_today": ", занята до конца дня",
+
+		"alert.subscribed": "Пришлю сообщение, когда аудитория освободится",
+		"alert.text":       "🔔 %d пара, %s\n%s",
+
+		"claim.usage":      "Укажи аудиторию, например: /claim 395ю",
+		"claim.busy":       "По расписанию в %s идёт пара, занять её нельзя",
+		"claim.rejected":   "Не получилось занять %s",
+		"claim.expired":    "Не получилось занять %s: пара уже закончилась",
+		"claim.too_long":   "Не получилось занять %s: занять можно не больше чем на %d часа",
+		"claim.limit":      "Не получилось занять %s: можно держать не больше %d аудиторий, сейчас у тебя %s. Освободить: /release %s",
+		"claim.done":       "%s отмечена занятой до %s. Освободить раньше: /release %s",
+		"release.usage":    "Укажи аудиторию, например: /release 395ю",
+		"release.done":     "%s снова свободна",
+		"report.usage":     "Сообщи, что на самом деле с аудиторией: /report 395ю занята|закрыта|свободна",
+		"report.mark":      " ⚠️ по отзывам занята (%.0f%%)",
+		"report.thanks":    "Спасибо! Отметка про %s на %d пару учтётся в поиске",
+		"report.buttons":   "Если аудитория на деле занята или закрыта, нажми на неё ниже",
+		"report.button":    "⚑ %s",
+		"report.ask":       "Что с %s на %d паре?",
+		"report.expired":   "Эта пара уже закончилась, повтори запрос",
+		"report.occupied":  "Занята",
+		"report.locked":    "Закрыта",
+		"report.free":      "Свободна",
+		"teacher.usage":    "Укажи преподавателя, например: /teacher Иванов",
+		"teacher.missing":  "Преподаватель не найден",
+		"teacher.choose":   "Уточни преподавателя",
+		"teacher.now_in":   "Сейчас в %s до %s",
+		"teacher.now_free": "Сейчас пар нет",
+		"teacher.next":     "далее %s в %s",
+		"teacher.week":     "\nЭта неделя, %s\n",
+		"group.usage":      "Укажи группу, например: /group ИУ9-62Б завтра\nили выбери свою в /settings",
+		"group.missing":    "Группа не найдена",
+		"group.choose":     "Уточни группу",
+		"group.title":      "Группа <b>%s</b>, %s",
+
+		"settings.text": "Настройки\n\nКорпус: %s\nЭтаж: %s\nГруппа: %s\nЯзык: %s\nУведомления: %s\n\n" +
+			"Группу можно выбрать командой /settings group ИУ9-62Б",
+		"settings.unset":         "не выбран",
+		"settings.unset_group":   "не выбрана",
+		"settings.language_auto": "как в Telegram",
+		"settings.on":            "включены",
+		"settings.off":           "выключены",
+		"settings.building":      "Корпус",
+		"settings.floor":         "Этаж",
+		"settings.language":      "Язык",
+		"settings.notifications": "Уведомления",
+		"settings.reset_group":   "Сбросить группу",
+		"settings.not_chosen":    "Не выбран",
+		"settings.invalid":       "Такое значение выбрать нельзя",
+
+		"inline.invalid":      "Не понял запрос",
+		"inline.example":      "Пример: улк 3 этаж 4 пара",
+		"inline.example_text": "Пример запроса: @BMSTURoomsBot улк 3 этаж 4 пара",
+		"inline.title":        "%s, %d этаж: %d своб.",
+		"inline.text":         "Свободные аудитории, %s\n%s\n%s",
+
+		"page.counter": "Страница %d из %d",
+		"page.expired": "Результат устарел, повтори запрос",
+
+		"console.help":    "Команды: /start, /now [корпус] [этаж], /exit. На вопросы отвечай номером кнопки",
+		"console.unknown": "Не понял, попробуй /start",
+
+		"admin.reload_unavailable": "schedule_dir не задан, перезагружать нечего",
+		"admin.reload_running":     "Импорт расписания уже идёт",
+		"admin.reload_started":     "Скачиваю и импортирую расписание, это займёт несколько минут",
+		"admin.reload_done":        "Импорт расписания закончен",
+		"admin.import": "Импорт от %s, шёл %s\nФайлов импортировано: %d из %d\n" +
+			"В карантине: %d файлов, %d занятий не совпали со звонками",
+		"admin.import_error":      "Ошибка: %s",
+		"admin.no_import":         "Расписание ещё ни разу не импортировалось",
+		"admin.last_import":       "Последний импорт: %s",
+		"admin.users":             "Пользователей: %d, новых за %[3]d дн.: %[2]d",
+		"admin.queries":           "Запросы за %d дн.:",
+		"admin.no_queries":        "запросов не было",
+		"admin.errors":            "Ошибки с запуска: пользовательские %d, временные %d, паники %d, прочие %d",
+		"admin.send_queue":        "Очередь отправки: ответы %d, уведомления %d, отправлено %d, 429 %d",
+		"admin.broadcast_usage":   "Напиши текст рассылки: /broadcast текст",
+		"admin.broadcast_started": "Рассылаю %d пользователям",
+		"admin.broadcast_done":    "Рассылка закончена: доставлено %d из %d",
+	},
+	service.LanguageEnglish: {
+		"error.generic":      "Something went wrong :(",
+		"error.retry_start":  "Something went wrong :(\nTry /start",
+		"error.stale_button": "This button is outdated",
+		"error.bad_choice":   "Didn't get the choice, try /start",
+
+		"query.week_day":     "Day of the week",
+		"query.week_type":    "Numerator or denominator week",
+		"query.building":     "Building",
+		"query.floor":        "Floor",
+		"query.period":       "Period",
+		"query.last_period":  "Until which period",
+		"query.any_building": "Any building",
+		"query.any_floor":    "Any floor",
+		"query.only_period":  "Only %d",
+
+		"query.building_rooms":   "%s · %d rooms",
+		"query.floor_rooms":      "Floor %d · %d rooms",
+		"query.floor_rooms_free": "Floor %d · %d rooms, %d free all day",
+
+		"periods.one":   "period %d",
+		"periods.range": "periods %d–%d",
+		"periods.list":  "periods %s",
+
+		"free.until":         "until %s (period %d)",
+		"free.until_day_end": "until the end of the day",
+		"free.none":          "No free rooms",
+		"free.none_for":      "No free rooms: %s",
+		"free.header":        "Free rooms, %s:",
+		"free.floor":         "%s, floor %d",
+		"free.claimed":       "Already taken by students:",
+		"free.claimed_line":  "%s (%s) — until %s",
+
+		"slot.in_progress": "Period %d is in progress (%s–%s, %s).\n",
+		"slot.next":        "No classes right now. The next one, period %d, starts %s at %s (%s).\n",
+		"day.today":        "today",
+		"day.tomorrow":     "tomorrow",
+		"day.no_classes":   "No classes",
+		"when.today":       "at %s",
+		"when.tomorrow":    "tomorrow at %s",
+		"when.week_day":    "%s at %s",
+
+		"weekday.Monday":    "Monday",
+		"weekday.Tuesday":   "Tuesday",
+		"weekday.Wednesday": "Wednesday",
+		"weekday.Thursday":  "Thursday",
+		"weekday.Friday":    "Friday",
+		"weekday.Saturday":  "Saturday",
+		"weekday.Sunday":    "Sunday",
+
+		"weekday_short.Monday":    "Mo",
+		"weekday_short.Tuesday":   "Tu",
+		"weekday_short.Wednesday": "We",
+		"weekday_short.Thursday":  "Th",
+		"weekday_short.Friday":    "Fr",
+		"weekday_short.Saturday":  "Sa",
+		"weekday_short.Sunday":    "Su",
+
+		"on_weekday.Monday":    "on Monday",
+		"on_weekday.Tuesday":   "on Tuesday",
+		"on_weekday.Wednesday": "on Wednesday",
+		"on_weekday.Thursday":  "on Thursday",
+		"on_weekday.Friday":    "on Friday",
+		"on_weekday.Saturday":  "on Saturday",
+		"on_weekday.Sunday":    "on Sunday",
+
+		"weektype." + service.WeekTypeNumerator:   "numerator",
+		"weektype." + service.WeekTypeDenominator: "denominator",
+
+		"building.ГЗ":  "Main building",
+		"building.УЛК": "ULK",
+
+		"kind.lecture":  "lecture hall",
+		"kind.computer": "computer class",
+		"kind.lab":      "lab",
+		"kind.seminar":  "seminar room",
+
+		"equipment.projector":  "projector",
+		"equipment.whiteboard": "whiteboard",
+		"equipment.computers":  "PCs",
+		"equipment.sockets":    "sockets",
+
+		"audience.capacity": "%d seats",
+
+		"now.usage": "Didn't get that. Example: /now ULK 3 or /now computer for 20 people",
+
+		"room.usage":        "Give a room, e.g. /room 395ю",
+		"room.not_found":    "Room not found",
+		"room.title":        "Room <b>%s</b> (%s), %s\n",
+		"room.free_week":    "The room is free all week",
+		"room.add_favorite": "☆ Add to favorites",
+
+		"fav.usage":        "Give a room, e.g. /fav 395ю",
+		"unfav.usage":      "Give a room, e.g. /unfav 395ю",
+		"fav.added":        "%s added to favorites, see /favs",
+		"fav.removed":      "%s removed from favorites",
+		"fav.empty":        "No favorite rooms yet. Add one: /fav 395ю",
+		"fav.added_button": "Added to favorites",
+		"fav.alert_button": "🔔 Tell me when %s is free",
+
+		"status.free":       "✅ %s — free %s",
+		"status.free_from":  ", free from period %d (%s)",
+		"status.busy_today": ", busy until the end of the day",
+
+		"alert.subscribed": "I'll message you when the room is free",
+		"alert.text":       "🔔 Period %d, %s\n%s",
+
+		"claim.usage":      "Give a room, e.g. /claim 395ю",
+		"claim.busy":       "There is a class in %s by the timetable, it cannot be claimed",
+		"claim.rejected":   "Cannot claim %s",
+		"claim.expired":    "Cannot claim %s: the period is already over",
+		"claim.too_long":   "Cannot claim %s: a room may be claimed for at most %d hours",
+		"claim.limit":      "Cannot claim %s: you may hold at most %d rooms and hold %s now. Release one: /release %s",
+		"claim.done":       "%s is marked taken until %s. Release earlier: /release %s",
+		"release.usage":    "Give a room, e.g. /release 395ю",
+		"release.done":     "%s is free again",
+		"report.usage":     "Tell what is really going on in a room: /report 395ю occupied|locked|free",
+		"report.mark":      " ⚠️ reported busy (%.0f%%)",
+		"report.thanks":    "Thanks! Your note about %s for period %d will be taken into account",
+		"report.buttons":   "If a room is actually occupied or locked, tap it below",
+		"report.button":    "⚑ %s",
+		"report.ask":       "What is going on in %s during period %d?",
+		"report.expired":   "This period is over, repeat the query",
+		"report.occupied":  "Occupied",
+		"report.locked":    "Locked",
+		"report.free":      "Free",
+		"teacher.usage":    "Give a teacher, e.g. /teacher Иванов",
+		"teacher.missing":  "Teacher not found",
+		"teacher.choose":   "Which teacher?",
+		"teacher.now_in":   "Now in %s until %s",
+		"teacher.now_free": "No classes now",
+		"teacher.next":     "next %s in %s",
+		"teacher.week":     "\nThis week, %s\n",
+		"group.usage":      "Give a group, e.g. /group ИУ9-62Б tomorrow\nor choose yours in /settings",
+		"group.missing":    "Group not found",
+		"group.choose":     "Which group?",
+		"group.title":      "Group <b>%s</b>, %s",
+
+		"settings.text": "Settings\n\nBuilding: %s\nFloor: %s\nGroup: %s\nLanguage: %s\nNotifications: %s\n\n" +
+			"Choose a group with /settings group ИУ9-62Б",
+		"settings.unset":         "not set",
+		"settings.unset_group":   "not set",
+		"settings.language_auto": "as in Telegram",
+		"settings.on":            "on",
+		"settings.off":           "off",
+		"settings.building":      "Building",
+		"settings.floor":         "Floor",
+		"settings.language":      "Language",
+		"settings.notifications": "Notifications",
+		"settings.reset_group":   "Reset group",
+		"settings.not_chosen":    "Not set",
+		"settings.invalid":       "This value cannot be chosen",
+
+		"inline.invalid":      "Didn't get the query",
+		"inline.example":      "Example: ulk floor 3 period 4",
+		"inline.example_text": "Query example: @BMSTURoomsBot ulk floor 3 period 4",
+		"inline.title":        "%s, floor %d: %d free",
+		"inline.text":         "Free rooms, %s\n%s\n%s",
+
+		"page.counter": "Page %d of %d",
+		"page.expired": "These results are outdated, repeat the query",
+
+		"console.help":    "Commands: /start, /now [building] [floor], /exit. Answer questions with the number of the button",
+		"console.unknown": "Didn't get that, try /start",
+
+		"admin.reload_unavailable": "schedule_dir is not set, there is nothing to reload",
+		"admin.reload_running":     "Schedule import is already running",
+		"admin.reload_started":     "Downloading and importing the schedule, it takes a few minutes",
+		"admin.reload_done":        "Schedule import finished",
+		"admin.import": "Import of %s, took %s\nFiles imported: %d of %d\n" +
+			"Quarantined: %d files, %d lessons matching no bell",
+		"admin.import_error":      "Error: %s",
+		"admin.no_import":         "The schedule has never been imported",
+		"admin.last_import":       "Last import: %s",
+		"admin.users":             "Users: %d, new in %[3]d days: %[2]d",
+		"admin.queries":           "Queries in %d days:",
+		"admin.no_queries":        "no queries",
+		"admin.errors":            "Errors since start: user %d, transient %d, panics %d, other %d",
+		"admin.send_queue":        "Send queue: replies %d, notifications %d, sent %d, 429 %d",
+		"admin.broadcast_usage":   "Write the text to send: /broadcast text",
+		"admin.broadcast_started": "Sending to %d users",
+		"admin.broadcast_done":    "Broadcast finished: delivered %d of %d",
+	},
+}
+
+// Localizer translates texts to the language of one user.
+type Localizer struct {
+	lang string
+}
+
+// New returns the localizer of lang, Russian if lang is not supported.
+func New(lang string) Localizer {
+	if _, ok := catalog[lang]; !ok {
+		lang = service.LanguageRussian
+	}
+	return Localizer{lang: lang}
+}
+
+func (l Localizer) Lang() string {
+	return l.lang
+}
+
+// T returns the text by key formatted with args.
+func (l Localizer) T(key string, args ...interface{}) string {
+	text, ok := catalog[l.lang][key]
+	if !ok {
+		text, ok = catalog[service.LanguageRussian][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+func (l Localizer) WeekDay(weekDay string) string {
+	return l.T("weekday." + weekDay)
+}
+
+func (l Localizer) WeekDayShort(weekDay string) string {
+	return l.T("weekday_short." + weekDay)
+}
+
+func (l Localizer) OnWeekDay(weekDay string) string {
+	return l.T("on_weekday." + weekDay)
+}
+
+func (l Localizer) WeekType(weekType string) string {
+	return l.T("weektype." + weekType)
+}
+
+// Building returns the name of the building as it is known in the language, unknown buildings are kept as is.
+func (l Localizer) Building(building string) string {
+	if _, ok := catalog[service.LanguageRussian]["building."+building]; !ok {
+		return building
+	}
+	return l.T("building." + building)
+}
+
+// LanguageFromCode picks the language for telegram language_code: Russian for
+// Russian speaking countries and for users who did not share it, English otherwise.
+func LanguageFromCode(code string) string {
+	base, _, _ := strings.Cut(strings.ToLower(code), "-")
+	switch base {
+	case "", "ru", "be", "uk", "kk", "ky", "uz", "tg":
+		return service.LanguageRussian
+	default:
+		return service.LanguageEnglish
+	}
+}
@@ -0,0 +1,128 @@
+// Package icsfeed renders timetables as iCalendar feeds which calendar apps can subscribe to.
+//
+// Every slot of the timetable becomes one event repeating every other week till the end of the
+// semester, so ЧС and ЗН lessons fall on their own weeks. Lessons of the military training centre
+// and elective physical education are not imported, so no feed has them.
+package icsfeed
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	ics "github.com/arran4/golang-ical"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+const (
+	timezone = "Europe/Moscow"
+	// refreshInterval asks calendar apps to fetch the feed again twice a day
+	refreshInterval = "PT12H"
+
+	localFormat = "20060102T150405"
+	utcFormat   = "20060102T150405Z"
+)
+
+// Feed is a timetable to render. ID tells feeds apart in event UIDs, e.g. "group:<id>",
+// so one lesson gets different UIDs in the feeds of its room and its group.
+type Feed struct {
+	ID      string
+	Name    string
+	Entries []service.ScheduleEntry
+}
+
+// uid stays the same while the slot, the lesson and the room do, so re-imports of the timetable
+// do not duplicate events in subscribed calendars.
+func uid(feedID string, e service.ScheduleEntry) string {
+	suffix := ""
+	if e.Audience.Suffix != nil {
+		suffix = *e.Audience.Suffix
+	}
+	h := sha1.New()
+	for _, part := range []string{feedID, e.WeekType, e.WeekDay, fmt.Sprint(e.Period), e.Lesson.Name, e.Audience.Number, suffix} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)) + "@bmstu-free-rooms"
+}
+
+func location(a service.Audience) string {
+	number := a.Number
+	if a.Suffix != nil {
+		number += *a.Suffix
+	}
+	return fmt.Sprintf("%s (%s)", number, a.Building)
+}
+
+func description(e service.ScheduleEntry) string {
+	lines := make([]string, 0, 3)
+	if e.Lesson.Kind != nil {
+		lines = append(lines, *e.Lesson.Kind)
+	}
+	if e.Lesson.TeacherName != nil {
+		lines = append(lines, *e.Lesson.TeacherName)
+	}
+	if len(e.Groups) > 0 {
+		lines = append(lines, strings.Join(e.Groups, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// moscow is the VTIMEZONE of the feed, Moscow has kept +03:00 all year since 2014.
+func moscow() *ics.VTimezone {
+	standard := &ics.Standard{}
+	standard.SetProperty("DTSTART", "19700101T000000")
+	standard.SetProperty("TZOFFSETFROM", "+0300")
+	standard.SetProperty("TZOFFSETTO", "+0300")
+	standard.SetProperty("TZNAME", "MSK")
+
+	tz := &ics.VTimezone{}
+	tz.SetProperty("TZID", timezone)
+	tz.Components = append(tz.Components, standard)
+	return tz
+}
+
+// Write renders the feed, now is its DTSTAMP.
+func Write(w io.Writer, calendar *service.Calendar, feed Feed, now time.Time) error {
+	cal := ics.NewCalendarFor("bmstu-free-rooms")
+	cal.SetMethod(ics.MethodPublish)
+	// unlike event setters, the calendar ones do not escape text
+	cal.SetXWRCalName(ics.ToText(feed.Name))
+	cal.SetXWRTimezone(timezone)
+	cal.SetRefreshInterval(refreshInterval)
+	cal.SetXPublishedTTL(refreshInterval)
+	cal.Components = append(cal.Components, moscow())
+
+	tzid := &ics.KeyValues{Key: string(ics.ParameterTzid), Value: []string{timezone}}
+	until := calendar.SemesterEnd().UTC().Format(utcFormat)
+	seen := make(map[string]bool)
+	for _, e := range feed.Entries {
+		id := uid(feed.ID, e)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		start, end, ok := calendar.NextOccurrence(calendar.SemesterStart(), e.WeekType, e.WeekDay, e.Period)
+		if !ok {
+			continue
+		}
+
+		event := cal.AddEvent(id)
+		event.SetDtStampTime(now)
+		event.SetProperty(ics.ComponentPropertyDtStart, start.In(service.MoscowLocation).Format(localFormat), tzid)
+		event.SetProperty(ics.ComponentPropertyDtEnd, end.In(service.MoscowLocation).Format(localFormat), tzid)
+		event.AddRrule("FREQ=WEEKLY;INTERVAL=2;UNTIL=" + until)
+		event.SetSummary(e.Lesson.Name)
+		event.SetLocation(location(e.Audience))
+		if d := description(e); d != "" {
+			event.SetDescription(d)
+		}
+	}
+
+	return cal.SerializeTo(w)
+}
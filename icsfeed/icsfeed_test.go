@@ -0,0 +1,154 @@
+package icsfeed
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlexisOMG/bmstu-free-rooms/service"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// the semester starts on Monday, August 31, a ЧС week
+var (
+	calendar = service.NewCalendar(time.Date(2026, time.August, 31, 0, 0, 0, 0, service.MoscowLocation))
+	stamp    = time.Date(2026, time.September, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func entry(weekType, weekDay string, period int, lesson, room string) service.ScheduleEntry {
+	return service.ScheduleEntry{
+		WeekType: weekType,
+		WeekDay:  weekDay,
+		Period:   period,
+		Lesson:   service.Lesson{Name: lesson, TeacherName: strPtr("Иванов И. И."), Kind: strPtr("лек")},
+		Audience: service.Audience{Number: room, Suffix: strPtr("ю"), Building: "ГЗ"},
+		Groups:   []string{"ИУ9-62Б", "ИУ9-61Б"},
+	}
+}
+
+func groupFeed() Feed {
+	return Feed{ID: "group:1", Name: "ИУ9-62Б", Entries: []service.ScheduleEntry{
+		entry(service.WeekTypeNumerator, "Monday", 1, "Базы данных", "501"),
+		entry(service.WeekTypeDenominator, "Tuesday", 2, "Компиляторы", "395"),
+	}}
+}
+
+func render(t *testing.T, feed Feed, now time.Time) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, calendar, feed, now); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	return buf.String()
+}
+
+func TestWriteGolden(t *testing.T) {
+	got := render(t, groupFeed(), stamp)
+
+	golden := filepath.Join("testdata", "group.ics")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("cannot read golden file, run with -update to create it: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("Write() differs from %s:\n%s", golden, got)
+	}
+}
+
+func TestWriteTimezone(t *testing.T) {
+	got := render(t, groupFeed(), stamp)
+	want := strings.Join([]string{
+		"BEGIN:VTIMEZONE",
+		"TZID:Europe/Moscow",
+		"BEGIN:STANDARD",
+		"DTSTART:19700101T000000",
+		"TZOFFSETFROM:+0300",
+		"TZOFFSETTO:+0300",
+		"TZNAME:MSK",
+		"END:STANDARD",
+		"END:VTIMEZONE",
+	}, "\r\n")
+	if !strings.Contains(got, want) {
+		t.Errorf("no Moscow VTIMEZONE in the feed:\n%s", got)
+	}
+}
+
+func TestWriteRecurrence(t *testing.T) {
+	got := render(t, groupFeed(), stamp)
+
+	// the semester ends after 17 weeks, at midnight in Moscow
+	end := calendar.SemesterEnd()
+	if want := time.Date(2026, time.December, 28, 0, 0, 0, 0, service.MoscowLocation); !end.Equal(want) {
+		t.Fatalf("SemesterEnd() = %v, want %v", end, want)
+	}
+	rrule := "RRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=20261227T210000Z\r\n"
+	if n := strings.Count(got, rrule); n != 2 {
+		t.Errorf("%q occurs %d times, want once per event", rrule, n)
+	}
+
+	for _, line := range []string{
+		// ЧС lessons start on the first week, ЗН ones on the second
+		"DTSTART;TZID=Europe/Moscow:20260831T083000\r\n",
+		"DTEND;TZID=Europe/Moscow:20260831T100500\r\n",
+		"DTSTART;TZID=Europe/Moscow:20260908T101500\r\n",
+		"DTEND;TZID=Europe/Moscow:20260908T115000\r\n",
+	} {
+		if !strings.Contains(got, line) {
+			t.Errorf("no %q in the feed:\n%s", line, got)
+		}
+	}
+}
+
+func TestUID(t *testing.T) {
+	e := entry(service.WeekTypeNumerator, "Monday", 1, "Базы данных", "501")
+	id := uid("group:1", e)
+
+	// a re-import brings new IDs and another teacher, the event stays the same
+	reimported := e
+	reimported.ScheduleID = "another"
+	reimported.Lesson.ID = "another"
+	reimported.Lesson.TeacherName = strPtr("Петров П. П.")
+	if got := uid("group:1", reimported); got != id {
+		t.Errorf("uid() after re-import = %s, want %s", got, id)
+	}
+
+	moved := e
+	moved.Audience.Number = "502"
+	changed := []struct {
+		name   string
+		feedID string
+		entry  service.ScheduleEntry
+	}{
+		{"another feed", "audience:1", e},
+		{"another room", "group:1", moved},
+	}
+	for _, tt := range changed {
+		if got := uid(tt.feedID, tt.entry); got == id {
+			t.Errorf("uid() for %s = %s, the same as the original", tt.name, got)
+		}
+	}
+
+	// feeds rendered at different times keep the UIDs, only DTSTAMP moves
+	first := render(t, groupFeed(), stamp)
+	second := render(t, groupFeed(), stamp.Add(24*time.Hour))
+	if !strings.Contains(first, "UID:"+id) || !strings.Contains(second, "UID:"+id) {
+		t.Errorf("UID:%s is missing from the feeds", id)
+	}
+	if strings.Replace(first, "20260901T120000Z", "20260902T120000Z", -1) != second {
+		t.Error("feeds rendered a day apart differ in more than DTSTAMP")
+	}
+}
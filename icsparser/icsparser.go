@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -30,15 +32,22 @@ type Data struct {
 }
 
 var (
-	milReg      = regexp.MustCompile(`(?i)вуц`)
 	cafReg      = regexp.MustCompile(`(?i)каф`)
-	peReg       = regexp.MustCompile(`(?i)Элективный курс по физической культуре и спорту`)
 	ulkReg      = regexp.MustCompile(`^[\d\.]+[лаб]$`)
 	gzReg       = regexp.MustCompile(`^[\d\.]+(ю|аю)?$`)
 	suffixReg   = regexp.MustCompile(`[а-яА-Я]+`)
 	scheduleReg = regexp.MustCompile(`^Расписание `)
 )
 
+// SplitLocation splits audience location like 395ю into number and building suffix.
+func SplitLocation(location string) (string, *string) {
+	if loc := suffixReg.FindStringIndex(location); loc != nil {
+		suffix := location[loc[0]:loc[1]]
+		return location[:loc[0]], &suffix
+	}
+	return location, nil
+}
+
 func parseICS(ctx context.Context, path string) (Data, error) {
 	d, err := ioutil.ReadFile(path)
 	if err != nil {
@@ -48,6 +57,9 @@ func parseICS(ctx context.Context, path string) (Data, error) {
 	res := Data{}
 
 	cal, err := ics.ParseCalendar(strings.NewReader(string(d)))
+	if err != nil {
+		return Data{}, err
+	}
 
 	for _, prop := range cal.CalendarProperties {
 		if prop.IANAToken == "X-WR-CALNAME" {
@@ -98,7 +110,7 @@ func parseICS(ctx context.Context, path string) (Data, error) {
 		}
 
 		if s.Name != "" && s.Location != "" && s.Start != nil && s.End != nil &&
-			!milReg.MatchString(s.Name) && !cafReg.MatchString(s.Location) && !peReg.MatchString(s.Name) &&
+			!service.IsMilitaryTraining(s.Name) && !cafReg.MatchString(s.Location) && !service.IsPhysicalEducation(s.Name) &&
 			(ulkReg.MatchString(s.Location) || gzReg.MatchString(s.Location)) {
 			res.Schedules = append(res.Schedules, s)
 		}
@@ -107,7 +119,8 @@ func parseICS(ctx context.Context, path string) (Data, error) {
 	return res, nil
 }
 
-func saveData(ctx context.Context, srvc *service.Service, data Data) error {
+// saveData stores the timetable of the group and returns how many events did not match any bell.
+func saveData(ctx context.Context, srvc *service.Service, data Data) (int, error) {
 	log := ctx.Value("logger").(*logrus.Logger)
 	var groupID string
 	if loc := scheduleReg.FindStringIndex(data.Group); loc != nil {
@@ -118,20 +131,21 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 			if errors.Is(err, service.ErrorNotFound) {
 				ids, err := srvc.SaveGroups(ctx, service.Group{Name: groupName})
 				if err != nil {
-					return err
+					return 0, err
 				}
 				gs = []service.Group{
 					{ID: ids[0]},
 				}
 			} else {
-				return err
+				return 0, err
 			}
 		}
 		groupID = gs[0].ID
 	} else {
-		return fmt.Errorf("invalid group name: %s", data.Group)
+		return 0, fmt.Errorf("invalid group name: %s", data.Group)
 	}
 
+	quarantined := 0
 	audienceIDs := make(map[string]string)
 	lessonIDs := make(map[string]string)
 	schs := map[string]map[int][]Schedule{
@@ -146,15 +160,7 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 
 	for _, schedule := range data.Schedules {
 		if _, ok := audienceIDs[schedule.Location]; !ok {
-			var number string
-			var suffix *string
-			if loc := suffixReg.FindStringIndex(schedule.Location); loc != nil {
-				number = schedule.Location[:loc[0]]
-				suf := schedule.Location[loc[0]:loc[1]]
-				suffix = &suf
-			} else {
-				number = schedule.Location
-			}
+			number, suffix := SplitLocation(schedule.Location)
 
 			aud, err := srvc.ListAudienceByNumber(ctx, number, suffix)
 			if err != nil {
@@ -164,11 +170,11 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 						Suffix: suffix,
 					})
 					if err != nil {
-						return err
+						return 0, err
 					}
 					aud.ID = ids[0]
 				} else {
-					return err
+					return 0, err
 				}
 			}
 			audienceIDs[schedule.Location] = aud.ID
@@ -180,7 +186,7 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 			}
 			ids, err := srvc.SaveLessons(ctx, lesson)
 			if err != nil {
-				return err
+				return 0, err
 			}
 			lessonIDs[schedule.Name] = ids[0]
 		}
@@ -206,6 +212,7 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 			period = 7
 		default:
 			log.WithError(fmt.Errorf("invalid start end: %v", schedule)).Warning("skip schedule")
+			quarantined++
 			continue
 		}
 
@@ -215,14 +222,14 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 	for _, schedule := range data.Schedules {
 		lessonID, ok := lessonIDs[schedule.Name]
 		if !ok {
-			return fmt.Errorf("unknown lesson: %s, group: %s", schedule.Name, data.Group)
+			return 0, fmt.Errorf("unknown lesson: %s, group: %s", schedule.Name, data.Group)
 		}
 		_, err := srvc.SaveGroupLessons(ctx, service.GroupLesson{
 			GroupID:  groupID,
 			LessonID: lessonID,
 		})
 		if err != nil {
-			return err
+			return 0, err
 		}
 	}
 
@@ -242,25 +249,25 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 				if id, ok := audienceIDs[schedules[0].Location]; ok {
 					s.AudienceID = id
 				} else {
-					return fmt.Errorf("unknown audince: %v", schedules[0])
+					return 0, fmt.Errorf("unknown audince: %v", schedules[0])
 				}
 
 				if id, ok := lessonIDs[schedules[0].Name]; ok {
 					s.LessonID = id
 				} else {
-					return fmt.Errorf("unknown lesson: %v", schedules[0])
+					return 0, fmt.Errorf("unknown lesson: %v", schedules[0])
 				}
 
 				_, err := srvc.SaveSchedules(ctx, s)
 				if err != nil {
-					return err
+					return 0, err
 				}
 
 				s.WeekType = "ЗН"
 
 				_, err = srvc.SaveSchedules(ctx, s)
 				if err != nil {
-					return err
+					return 0, err
 				}
 			case 2:
 				s1 := service.Schedule{
@@ -272,13 +279,13 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 				if id, ok := audienceIDs[schedules[0].Location]; ok {
 					s1.AudienceID = id
 				} else {
-					return fmt.Errorf("unknown audince: %v", schedules[0])
+					return 0, fmt.Errorf("unknown audince: %v", schedules[0])
 				}
 
 				if id, ok := lessonIDs[schedules[0].Name]; ok {
 					s1.LessonID = id
 				} else {
-					return fmt.Errorf("unknown lesson: %v", schedules[0])
+					return 0, fmt.Errorf("unknown lesson: %v", schedules[0])
 				}
 
 				s2 := service.Schedule{
@@ -290,13 +297,13 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 				if id, ok := audienceIDs[schedules[1].Location]; ok {
 					s2.AudienceID = id
 				} else {
-					return fmt.Errorf("unknown audince: %v", schedules[1])
+					return 0, fmt.Errorf("unknown audince: %v", schedules[1])
 				}
 
 				if id, ok := lessonIDs[schedules[1].Name]; ok {
 					s2.LessonID = id
 				} else {
-					return fmt.Errorf("unknown lesson: %v", schedules[1])
+					return 0, fmt.Errorf("unknown lesson: %v", schedules[1])
 				}
 
 				if s1.Start.Before(*s2.Start) {
@@ -308,23 +315,30 @@ func saveData(ctx context.Context, srvc *service.Service, data Data) error {
 				}
 				_, err := srvc.SaveSchedules(ctx, s1, s2)
 				if err != nil {
-					return fmt.Errorf("cannot save 2 schedules: %w", err)
+					return 0, fmt.Errorf("cannot save 2 schedules: %w", err)
 				}
 			default:
-				return fmt.Errorf("smth went wrong: %v", data)
+				return 0, fmt.Errorf("smth went wrong: %v", data)
 			}
 		}
 	}
 
-	return nil
+	return quarantined, nil
 }
 
-func ProcessICSFiles(ctx context.Context, srvc *service.Service, pathToICS string) error {
+// quarantineDir is the subdirectory of the schedule directory broken files are moved to.
+const quarantineDir = "quarantine"
+
+// ProcessICSFiles imports every ics file of the directory. Files which cannot be parsed or saved
+// are moved to the quarantine subdirectory and do not stop the import, storage failures worth
+// retrying do.
+func ProcessICSFiles(ctx context.Context, srvc *service.Service, pathToICS string) (service.ScheduleImport, error) {
 	log := ctx.Value("logger").(*logrus.Logger)
+	res := service.ScheduleImport{StartedAt: time.Now()}
 
 	files, err := ioutil.ReadDir(pathToICS)
 	if err != nil {
-		return fmt.Errorf("failed to read dir with ics files: %w", err)
+		return res, fmt.Errorf("failed to read dir with ics files: %w", err)
 	}
 	schedules := make([]string, 0, 1024)
 
@@ -333,19 +347,41 @@ func ProcessICSFiles(ctx context.Context, srvc *service.Service, pathToICS strin
 			schedules = append(schedules, pathToICS+"/"+f.Name())
 		}
 	}
+	res.Files = len(schedules)
 
 	log.WithField("schedules_count", len(schedules)).Info("count of ics files in schedule dir")
 
 	for _, s := range schedules {
 		d, err := parseICS(ctx, s)
 		if err != nil {
-			return fmt.Errorf("failed to parse %s: %w", s, err)
+			res.QuarantinedFiles++
+			quarantine(log, pathToICS, s, fmt.Errorf("failed to parse: %w", err))
+			continue
+		}
+		skipped, err := saveData(ctx, srvc, d)
+		if errors.Is(err, service.ErrorTransient) {
+			return res, fmt.Errorf("failed to save %s in db: %w", s, err)
 		}
-		err = saveData(ctx, srvc, d)
 		if err != nil {
-			return fmt.Errorf("failed to save %s in db: %w", s, err)
+			res.QuarantinedFiles++
+			quarantine(log, pathToICS, s, fmt.Errorf("failed to save in db: %w", err))
+			continue
 		}
+		res.Imported++
+		res.QuarantinedEvents += skipped
 	}
 
-	return nil
+	return res, nil
+}
+
+func quarantine(log *logrus.Logger, pathToICS, file string, reason error) {
+	log.WithError(reason).WithField("file", file).Warning("quarantine ics file")
+	dir := filepath.Join(pathToICS, quarantineDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.WithError(err).Error("cannot create quarantine dir")
+		return
+	}
+	if err := os.Rename(file, filepath.Join(dir, filepath.Base(file))); err != nil {
+		log.WithError(err).Error("cannot move ics file to quarantine")
+	}
 }
@@ -4,9 +4,14 @@ CREATE TABLE IF NOT EXISTS user_info (
   username VARCHAR,
   firstname VARCHAR,
   lastname VARCHAR,
+  phone VARCHAR,
   CONSTRAINT telegram_unique UNIQUE(telegram_id)
 );
 
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS phone VARCHAR;
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now();
+
 CREATE TABLE IF NOT EXISTS audience (
   id UUID PRIMARY KEY,
   number VARCHAR NOT NULL,
@@ -16,6 +21,10 @@ CREATE TABLE IF NOT EXISTS audience (
   CONSTRAINT audience_unique UNIQUE(number, suffix)
 );
 
+ALTER TABLE audience ADD COLUMN IF NOT EXISTS capacity INTEGER;
+ALTER TABLE audience ADD COLUMN IF NOT EXISTS kind VARCHAR;
+ALTER TABLE audience ADD COLUMN IF NOT EXISTS equipment VARCHAR[] NOT NULL DEFAULT '{}';
+
 CREATE TABLE IF NOT EXISTS lesson (
   id UUID PRIMARY KEY,
   name VARCHAR NOT NULL,
@@ -46,8 +55,86 @@ CREATE TABLE IF NOT EXISTS group_lesson (
   CONSTRAINT group_lesson_unique UNIQUE(group_id, lesson_id)
 );
 
+CREATE TABLE IF NOT EXISTS user_preferences (
+  user_id UUID PRIMARY KEY REFERENCES user_info(id),
+  default_building VARCHAR,
+  default_floor INTEGER,
+  group_id UUID REFERENCES groups(id),
+  language VARCHAR,
+  notifications BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE TABLE IF NOT EXISTS favorite_audience (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES user_info(id),
+  audience_id UUID NOT NULL REFERENCES audience(id),
+  CONSTRAINT favorite_audience_unique UNIQUE(user_id, audience_id)
+);
+
+CREATE TABLE IF NOT EXISTS free_alert (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES user_info(id),
+  audience_id UUID NOT NULL REFERENCES audience(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  CONSTRAINT free_alert_unique UNIQUE(user_id, audience_id)
+);
+
+CREATE TABLE IF NOT EXISTS room_claim (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES user_info(id),
+  audience_id UUID NOT NULL REFERENCES audience(id),
+  claimed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  CONSTRAINT room_claim_unique UNIQUE(user_id, audience_id)
+);
+
+CREATE TABLE IF NOT EXISTS room_report (
+  id UUID PRIMARY KEY,
+  user_id UUID NOT NULL REFERENCES user_info(id),
+  audience_id UUID NOT NULL REFERENCES audience(id),
+  week_type VARCHAR NOT NULL,
+  week_day VARCHAR NOT NULL,
+  period INTEGER NOT NULL,
+  state VARCHAR NOT NULL,
+  reported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS reporter_reputation (
+  user_id UUID PRIMARY KEY REFERENCES user_info(id),
+  agreed INTEGER NOT NULL DEFAULT 0,
+  total INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS query_stats (
+  day DATE NOT NULL,
+  command VARCHAR NOT NULL,
+  count INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (day, command)
+);
+
+CREATE TABLE IF NOT EXISTS schedule_import (
+  id UUID PRIMARY KEY,
+  started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  files INTEGER NOT NULL,
+  imported INTEGER NOT NULL,
+  quarantined_files INTEGER NOT NULL,
+  quarantined_events INTEGER NOT NULL,
+  error VARCHAR
+);
+
+CREATE TABLE IF NOT EXISTS bot_update_offset (
+  bot_id BIGINT PRIMARY KEY,
+  update_id BIGINT NOT NULL,
+  saved_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
 CREATE INDEX IF NOT EXISTS schedule_week_type_idx ON schedule USING btree (week_type);
 CREATE INDEX IF NOT EXISTS schedule_weekday_idx ON schedule USING btree (week_day);
 CREATE INDEX IF NOT EXISTS schedule_period_idx ON schedule USING btree (period);
 CREATE INDEX IF NOT EXISTS audience_building_idx ON audience USING btree (building);
 CREATE INDEX IF NOT EXISTS audience_floor_idx ON audience USING btree (floor);
+CREATE INDEX IF NOT EXISTS audience_equipment_idx ON audience USING gin (equipment);
+CREATE INDEX IF NOT EXISTS room_claim_expires_idx ON room_claim USING btree (audience_id, expires_at);
+CREATE INDEX IF NOT EXISTS room_claim_expires_at_idx ON room_claim USING btree (expires_at);
+CREATE INDEX IF NOT EXISTS room_report_slot_idx ON room_report USING btree (audience_id, week_type, week_day, period);
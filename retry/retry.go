@@ -0,0 +1,30 @@
+// Package retry repeats calls which fail for a reason that may go away.
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Do calls f until it succeeds, fails with an error retryable does not accept or has been called
+// attempts times. It waits backoff before the second call and twice as long before every next one,
+// ctx being done stops the wait and returns the last error.
+//
+// Only the caller knows whether f may be repeated: a write lost together with the connection
+// may have been applied anyway, retryable must not accept such failures.
+func Do(ctx context.Context, attempts int, backoff time.Duration, retryable func(error) bool, f func() error) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = f(); err == nil || !retryable(err) || attempt == attempts-1 {
+			return err
+		}
+		timer := time.NewTimer(backoff << attempt)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+	return err
+}
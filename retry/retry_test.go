@@ -0,0 +1,62 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var (
+	errTransient = errors.New("connection reset")
+	errPermanent = errors.New("no rows")
+)
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name  string
+		errs  []error
+		calls int
+		err   error
+	}{
+		{"success", []error{nil}, 1, nil},
+		{"transient then success", []error{errTransient, errTransient, nil}, 3, nil},
+		{"not retryable", []error{errPermanent}, 1, errPermanent},
+		{"retryable in a wrapped error", []error{fmt.Errorf("cannot commit: %w", errTransient), nil}, 2, nil},
+		{"attempts run out", []error{errTransient, errTransient, errTransient, nil}, 3, errTransient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), 3, time.Millisecond, isTransient, func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Errorf("Do() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDoStopsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Do(ctx, 3, time.Hour, isTransient, func() error {
+		calls++
+		return errTransient
+	})
+	if calls != 1 || !errors.Is(err, errTransient) {
+		t.Errorf("Do() after cancel = %v after %d calls, want the first error", err, calls)
+	}
+}
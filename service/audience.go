@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,12 +20,88 @@ var (
 	}
 )
 
+// Kinds of audiences.
+const (
+	AudienceKindLecture  = "lecture"
+	AudienceKindComputer = "computer"
+	AudienceKindLab      = "lab"
+	AudienceKindSeminar  = "seminar"
+)
+
+// Equipment of audiences.
+const (
+	EquipmentProjector  = "projector"
+	EquipmentWhiteboard = "whiteboard"
+	EquipmentComputers  = "computers"
+	EquipmentSockets    = "sockets"
+)
+
+var (
+	audienceKinds = []string{AudienceKindLecture, AudienceKindComputer, AudienceKindLab, AudienceKindSeminar}
+	equipment     = []string{EquipmentProjector, EquipmentWhiteboard, EquipmentComputers, EquipmentSockets}
+)
+
+// AudienceKinds returns all kinds of audiences.
+func AudienceKinds() []string {
+	return append([]string(nil), audienceKinds...)
+}
+
+// Equipment returns all kinds of equipment.
+func Equipment() []string {
+	return append([]string(nil), equipment...)
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
 type Audience struct {
 	ID       string
 	Number   string
 	Building string
 	Floor    int
 	Suffix   *string
+	// Capacity, Kind and Equipment are imported from the audience CSV, they are unknown for most audiences
+	Capacity  *int
+	Kind      *string
+	Equipment []string
+}
+
+// AudienceAttributes describes an audience the timetable knows nothing about.
+type AudienceAttributes struct {
+	AudienceID string
+	Capacity   *int
+	Kind       *string
+	Equipment  []string
+}
+
+func (a *AudienceAttributes) validate() error {
+	if a.Capacity != nil && *a.Capacity <= 0 {
+		return &ValidationError{
+			ObjectKind: "AudienceAttributes",
+			Message:    fmt.Sprintf("invalid capacity %d", *a.Capacity),
+		}
+	}
+	if a.Kind != nil && !contains(audienceKinds, *a.Kind) {
+		return &ValidationError{
+			ObjectKind: "AudienceAttributes",
+			Message:    fmt.Sprintf("unknown kind %s", *a.Kind),
+		}
+	}
+	for _, e := range a.Equipment {
+		if !contains(equipment, e) {
+			return &ValidationError{
+				ObjectKind: "AudienceAttributes",
+				Message:    fmt.Sprintf("unknown equipment %s", e),
+			}
+		}
+	}
+	return nil
 }
 
 func (a *Audience) fillCalculatedFields() error {
@@ -78,14 +156,225 @@ func (s *Service) ListAudienceByNumber(ctx context.Context, number string, suffi
 	return s.scheduleStorage.ListAudienceByNumber(ctx, number, suffix)
 }
 
+func (s *Service) GetAudience(ctx context.Context, id string) (Audience, error) {
+	return s.scheduleStorage.GetAudience(ctx, id)
+}
+
+// Floor is a floor of a building which has audiences.
+type Floor struct {
+	Building  string
+	Floor     int
+	Audiences int
+}
+
+// Building is a building which has audiences.
+type Building struct {
+	Name      string
+	Audiences int
+	Floors    []Floor
+}
+
+// ListFloors returns floors with audiences ordered by building and floor, all buildings if buildings is empty.
+func (s *Service) ListFloors(ctx context.Context, buildings ...string) ([]Floor, error) {
+	return s.scheduleStorage.ListFloors(ctx, buildings)
+}
+
+// ListBuildings returns buildings with audiences and their floors.
+func (s *Service) ListBuildings(ctx context.Context) ([]Building, error) {
+	floors, err := s.scheduleStorage.ListFloors(ctx, nil)
+	if err != nil {
+		return []Building{}, err
+	}
+
+	res := make([]Building, 0)
+	for _, f := range floors {
+		if len(res) == 0 || res[len(res)-1].Name != f.Building {
+			res = append(res, Building{Name: f.Building})
+		}
+		b := &res[len(res)-1]
+		b.Audiences += f.Audiences
+		b.Floors = append(b.Floors, f)
+	}
+	return res, nil
+}
+
+// SaveAudienceAttributes replaces capacity, kind and equipment of the audience.
+func (s *Service) SaveAudienceAttributes(ctx context.Context, attrs *AudienceAttributes) error {
+	if err := attrs.validate(); err != nil {
+		return err
+	}
+	return s.scheduleStorage.SaveAudienceAttributes(ctx, attrs)
+}
+
+// EmptyAudiencesFilter selects audiences free for every requested period on every requested day.
+// Empty Buildings, Floors or WeekDays mean any building, any floor or all study days.
 type EmptyAudiencesFilter struct {
-	Building string
-	WeekType string
-	WeekDay  string
-	Period   int
-	Floor    int
+	Buildings []string
+	Floors    []int
+	WeekType  string
+	WeekDays  []string
+	// Periods lists periods the audience must be free for, e.g. 3, 4, 5 for a double block
+	Periods []int
+	// ClaimsAt makes claims active at the moment count, nil ignores claims
+	ClaimsAt *time.Time
+	// HideClaimed drops claimed audiences from the result instead of marking them
+	HideClaimed bool
+	// MinCapacity keeps audiences for at least so many people, audiences of unknown capacity are dropped
+	MinCapacity int
+	// Kinds keeps audiences of any of the kinds
+	Kinds []string
+	// Equipment keeps audiences having all of the equipment
+	Equipment []string
 }
 
-func (s *Service) ListEmptyAudiences(ctx context.Context, filters *EmptyAudiencesFilter) ([]Audience, error) {
-	return s.scheduleStorage.ListEmptyAudiences(ctx, filters)
+// Covers tells whether the filter asks about the slot.
+func (f *EmptyAudiencesFilter) Covers(slot Slot) bool {
+	if f.WeekType != slot.WeekType {
+		return false
+	}
+	day, period := false, false
+	for _, d := range f.WeekDays {
+		day = day || d == slot.WeekDay
+	}
+	for _, p := range f.Periods {
+		period = period || p == slot.Period
+	}
+	return day && period
+}
+
+// PeriodRange returns periods from..to inclusive, clamped to the periods of Bells.
+func PeriodRange(from, to int) []int {
+	if from < 1 {
+		from = 1
+	}
+	if to > len(Bells) {
+		to = len(Bells)
+	}
+	if from > to {
+		return nil
+	}
+	res := make([]int, 0, to-from+1)
+	for p := from; p <= to; p++ {
+		res = append(res, p)
+	}
+	return res
+}
+
+func (f *EmptyAudiencesFilter) normalize() error {
+	if len(f.Periods) == 0 {
+		return &ValidationError{
+			ObjectKind: "EmptyAudiencesFilter",
+			Message:    "no periods",
+		}
+	}
+
+	periods := make([]int, 0, len(f.Periods))
+	for _, p := range f.Periods {
+		if _, ok := BellByPeriod(p); !ok {
+			return &ValidationError{
+				ObjectKind: "EmptyAudiencesFilter",
+				Message:    fmt.Sprintf("unknown period %d", p),
+			}
+		}
+		periods = append(periods, p)
+	}
+	sort.Ints(periods)
+
+	f.Periods = periods[:0]
+	for i, p := range periods {
+		if i == 0 || p != periods[i-1] {
+			f.Periods = append(f.Periods, p)
+		}
+	}
+
+	for _, k := range f.Kinds {
+		if !contains(audienceKinds, k) {
+			return &ValidationError{
+				ObjectKind: "EmptyAudiencesFilter",
+				Message:    fmt.Sprintf("unknown kind %s", k),
+			}
+		}
+	}
+	for _, e := range f.Equipment {
+		if !contains(equipment, e) {
+			return &ValidationError{
+				ObjectKind: "EmptyAudiencesFilter",
+				Message:    fmt.Sprintf("unknown equipment %s", e),
+			}
+		}
+	}
+
+	return f.validateWeekDays()
+}
+
+func (f *EmptyAudiencesFilter) validateWeekDays() error {
+	for _, d := range f.WeekDays {
+		if weekDayIndex(d) == len(weekDays) {
+			return &ValidationError{
+				ObjectKind: "EmptyAudiencesFilter",
+				Message:    fmt.Sprintf("unknown week day %s", d),
+			}
+		}
+	}
+	return nil
+}
+
+// LastPeriod returns the latest requested period.
+func (f *EmptyAudiencesFilter) LastPeriod() int {
+	if len(f.Periods) == 0 {
+		return 0
+	}
+	return f.Periods[len(f.Periods)-1]
+}
+
+// AudienceGroup is a set of empty audiences on one floor of a building.
+type AudienceGroup struct {
+	Building  string
+	Floor     int
+	Audiences []EmptyAudience
+}
+
+// GroupByFloor splits audiences ordered by building and floor into per-floor groups.
+func GroupByFloor(audiences []EmptyAudience) []AudienceGroup {
+	res := make([]AudienceGroup, 0)
+	for _, a := range audiences {
+		if len(res) == 0 || res[len(res)-1].Building != a.Building || res[len(res)-1].Floor != a.Floor {
+			res = append(res, AudienceGroup{
+				Building: a.Building,
+				Floor:    a.Floor,
+			})
+		}
+		res[len(res)-1].Audiences = append(res[len(res)-1].Audiences, a)
+	}
+	return res
+}
+
+// EmptyAudience is an audience free for every requested period.
+type EmptyAudience struct {
+	Audience
+	// NextBusyPeriod is the first occupied period after the requested ones, 0 if it stays free till the end of the day
+	NextBusyPeriod int
+	// ClaimedUntil is set if some user has claimed the audience
+	ClaimedUntil *time.Time
+	// UnavailableConfidence estimates from user reports how likely the audience is occupied or locked anyway
+	UnavailableConfidence float64
+}
+
+// Suspicious tells whether users reported the audience unavailable often enough to doubt the timetable.
+func (a *EmptyAudience) Suspicious() bool {
+	return a.UnavailableConfidence >= UnavailableConfidence
+}
+
+func (s *Service) ListEmptyAudiences(ctx context.Context, filters *EmptyAudiencesFilter) ([]EmptyAudience, error) {
+	if err := filters.normalize(); err != nil {
+		return []EmptyAudience{}, err
+	}
+	res, err := s.scheduleStorage.ListEmptyAudiences(ctx, filters)
+	if err != nil {
+		return []EmptyAudience{}, err
+	}
+	if err := s.applyReports(ctx, filters, res); err != nil {
+		return []EmptyAudience{}, err
+	}
+	return res, nil
 }
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeriodRange(t *testing.T) {
+	tests := []struct {
+		from, to int
+		want     []int
+	}{
+		{2, 4, []int{2, 3, 4}},
+		{3, 3, []int{3}},
+		{4, 2, nil},
+		{0, 2, []int{1, 2}},
+		{6, 3000000000, []int{6, 7}},
+		{8, 9, nil},
+	}
+	for _, tt := range tests {
+		if got := PeriodRange(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PeriodRange(%d, %d) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+var (
+	MoscowLocation = time.FixedZone("MSK", 3*60*60)
+
+	weekDays = []time.Weekday{
+		time.Monday,
+		time.Tuesday,
+		time.Wednesday,
+		time.Thursday,
+		time.Friday,
+		time.Saturday,
+	}
+)
+
+const (
+	WeekTypeNumerator   = "ЧС"
+	WeekTypeDenominator = "ЗН"
+
+	// SemesterWeeks is how many study weeks a semester has
+	SemesterWeeks = 17
+)
+
+// Bell describes start and end of one period as an offset from midnight (Moscow time).
+type Bell struct {
+	Period int
+	Start  time.Duration
+	End    time.Duration
+}
+
+func bell(period, hStart, mStart, hEnd, mEnd int) Bell {
+	return Bell{
+		Period: period,
+		Start:  time.Duration(hStart)*time.Hour + time.Duration(mStart)*time.Minute,
+		End:    time.Duration(hEnd)*time.Hour + time.Duration(mEnd)*time.Minute,
+	}
+}
+
+var Bells = []Bell{
+	bell(1, 8, 30, 10, 5),
+	bell(2, 10, 15, 11, 50),
+	bell(3, 12, 0, 13, 35),
+	bell(4, 13, 50, 15, 25),
+	bell(5, 15, 40, 17, 15),
+	bell(6, 17, 25, 19, 0),
+	bell(7, 19, 10, 20, 45),
+}
+
+func BellByPeriod(period int) (Bell, bool) {
+	if period < 1 || period > len(Bells) {
+		return Bell{}, false
+	}
+	return Bells[period-1], true
+}
+
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func (b Bell) StartString() string {
+	return formatClock(b.Start)
+}
+
+func (b Bell) EndString() string {
+	return formatClock(b.End)
+}
+
+// At returns start and end of the period on the day of t.
+func (b Bell) At(t time.Time) (time.Time, time.Time) {
+	t = t.In(MoscowLocation)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MoscowLocation)
+	return midnight.Add(b.Start), midnight.Add(b.End)
+}
+
+// Slot is a point of the weekly timetable.
+type Slot struct {
+	WeekType string
+	WeekDay  string
+	Period   int
+	// InProgress is false when the moment falls between periods and Period is the upcoming one.
+	InProgress bool
+	Start      time.Time
+	End        time.Time
+}
+
+type Calendar struct {
+	semesterStart time.Time
+}
+
+func NewCalendar(semesterStart time.Time) *Calendar {
+	start := semesterStart.In(MoscowLocation)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, MoscowLocation)
+	// weeks are counted from monday of the first study week
+	offset := (int(start.Weekday()) + 6) % 7
+	return &Calendar{
+		semesterStart: start.AddDate(0, 0, -offset),
+	}
+}
+
+// DefaultSemesterStart guesses the start of the semester going on at t: September 1 for the autumn
+// semester, which lasts till the end of January, and the first Monday of February for the spring one.
+func DefaultSemesterStart(t time.Time) time.Time {
+	t = t.In(MoscowLocation)
+	switch {
+	case t.Month() >= time.September:
+		return time.Date(t.Year(), time.September, 1, 0, 0, 0, 0, MoscowLocation)
+	case t.Month() == time.January:
+		return time.Date(t.Year()-1, time.September, 1, 0, 0, 0, 0, MoscowLocation)
+	default:
+		february := time.Date(t.Year(), time.February, 1, 0, 0, 0, 0, MoscowLocation)
+		return february.AddDate(0, 0, (8-int(february.Weekday()))%7)
+	}
+}
+
+// ParseSemesterStart parses semester_start of a config in 2006-01-02 format. Without it the start
+// is guessed from now and guessed is true, so older configs keep working.
+func ParseSemesterStart(value *string, now time.Time) (start time.Time, guessed bool, err error) {
+	if value == nil {
+		return DefaultSemesterStart(now), true, nil
+	}
+	start, err = time.ParseInLocation("2006-01-02", *value, MoscowLocation)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid semester start: %w", err)
+	}
+	return start, false, nil
+}
+
+func (c *Calendar) SemesterStart() time.Time {
+	return c.semesterStart
+}
+
+// SemesterEnd returns the moment after the last study week.
+func (c *Calendar) SemesterEnd() time.Time {
+	return c.semesterStart.AddDate(0, 0, 7*SemesterWeeks)
+}
+
+// WeekType returns ЧС for odd weeks of the semester and ЗН for even ones.
+func (c *Calendar) WeekType(t time.Time) string {
+	t = t.In(MoscowLocation)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, MoscowLocation)
+	days := int(math.Round(day.Sub(c.semesterStart).Hours() / 24))
+	week := days / 7
+	if days < 0 && days%7 != 0 {
+		week--
+	}
+	if week%2 == 0 {
+		return WeekTypeNumerator
+	}
+	return WeekTypeDenominator
+}
+
+// CurrentSlot returns the period in progress at t or the next one that starts on a study day.
+func (c *Calendar) CurrentSlot(t time.Time) Slot {
+	t = t.In(MoscowLocation)
+	day := t
+	for i := 0; i < 8; i++ {
+		if day.Weekday() != time.Sunday {
+			for _, b := range Bells {
+				start, end := b.At(day)
+				if !t.Before(end) {
+					continue
+				}
+				return Slot{
+					WeekType:   c.WeekType(day),
+					WeekDay:    day.Weekday().String(),
+					Period:     b.Period,
+					InProgress: !t.Before(start),
+					Start:      start,
+					End:        end,
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return Slot{}
+}
+
+// NextOccurrence returns the nearest moment at or after t when the given slot starts.
+func (c *Calendar) NextOccurrence(t time.Time, weekType, weekDay string, period int) (time.Time, time.Time, bool) {
+	b, ok := BellByPeriod(period)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	day := t.In(MoscowLocation)
+	for i := 0; i < 15; i++ {
+		if day.Weekday().String() == weekDay && c.WeekType(day) == weekType {
+			start, end := b.At(day)
+			if t.Before(end) {
+				return start, end, true
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+func weekDayIndex(weekDay string) int {
+	for i, d := range weekDays {
+		if d.String() == weekDay {
+			return i
+		}
+	}
+	return len(weekDays)
+}
+
+func WeekDays() []string {
+	res := make([]string, 0, len(weekDays))
+	for _, d := range weekDays {
+		res = append(res, d.String())
+	}
+	return res
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func moscowTime(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, MoscowLocation)
+}
+
+func TestCalendarWeekType(t *testing.T) {
+	// 2026-09-02 is a wednesday, weeks are counted from monday 2026-08-31
+	c := NewCalendar(moscowTime(2026, time.September, 2, 0, 0))
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"monday of the first week", moscowTime(2026, time.August, 31, 9, 0), WeekTypeNumerator},
+		{"sunday of the first week", moscowTime(2026, time.September, 6, 23, 59), WeekTypeNumerator},
+		{"second week", moscowTime(2026, time.September, 7, 0, 0), WeekTypeDenominator},
+		{"third week", moscowTime(2026, time.September, 16, 12, 0), WeekTypeNumerator},
+		{"week before the semester", moscowTime(2026, time.August, 30, 12, 0), WeekTypeDenominator},
+		{"two weeks before the semester", moscowTime(2026, time.August, 17, 12, 0), WeekTypeNumerator},
+		{"utc evening is the next moscow day", time.Date(2026, time.September, 6, 22, 0, 0, 0, time.UTC), WeekTypeDenominator},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.WeekType(tt.at); got != tt.want {
+				t.Errorf("WeekType(%v) = %s, want %s", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalendarCurrentSlot(t *testing.T) {
+	c := NewCalendar(moscowTime(2026, time.August, 31, 0, 0))
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want Slot
+	}{
+		{
+			name: "during a period",
+			at:   moscowTime(2026, time.September, 7, 9, 0),
+			want: Slot{WeekType: WeekTypeDenominator, WeekDay: "Monday", Period: 1, InProgress: true},
+		},
+		{
+			name: "between periods",
+			at:   moscowTime(2026, time.September, 7, 10, 10),
+			want: Slot{WeekType: WeekTypeDenominator, WeekDay: "Monday", Period: 2},
+		},
+		{
+			name: "before the first period",
+			at:   moscowTime(2026, time.September, 7, 7, 0),
+			want: Slot{WeekType: WeekTypeDenominator, WeekDay: "Monday", Period: 1},
+		},
+		{
+			name: "end of the last period",
+			at:   moscowTime(2026, time.September, 7, 20, 45),
+			want: Slot{WeekType: WeekTypeDenominator, WeekDay: "Tuesday", Period: 1},
+		},
+		{
+			name: "end of day",
+			at:   moscowTime(2026, time.September, 7, 23, 0),
+			want: Slot{WeekType: WeekTypeDenominator, WeekDay: "Tuesday", Period: 1},
+		},
+		{
+			name: "saturday evening rolls over sunday into the next week",
+			at:   moscowTime(2026, time.September, 12, 21, 0),
+			want: Slot{WeekType: WeekTypeNumerator, WeekDay: "Monday", Period: 1},
+		},
+		{
+			name: "sunday",
+			at:   moscowTime(2026, time.September, 13, 12, 0),
+			want: Slot{WeekType: WeekTypeNumerator, WeekDay: "Monday", Period: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.CurrentSlot(tt.at)
+			got.Start, got.End = time.Time{}, time.Time{}
+			if got != tt.want {
+				t.Errorf("CurrentSlot(%v) = %+v, want %+v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultSemesterStart(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want time.Time
+	}{
+		{moscowTime(2026, time.October, 19, 12, 0), moscowTime(2026, time.September, 1, 0, 0)},
+		{moscowTime(2027, time.January, 20, 12, 0), moscowTime(2026, time.September, 1, 0, 0)},
+		// february 1 is a sunday in 2026 and a monday in 2027
+		{moscowTime(2026, time.June, 15, 12, 0), moscowTime(2026, time.February, 2, 0, 0)},
+		{moscowTime(2027, time.March, 10, 12, 0), moscowTime(2027, time.February, 1, 0, 0)},
+		{moscowTime(2026, time.August, 31, 23, 0), moscowTime(2026, time.February, 2, 0, 0)},
+	}
+	for _, tt := range tests {
+		if got := DefaultSemesterStart(tt.at); !got.Equal(tt.want) {
+			t.Errorf("DefaultSemesterStart(%v) = %v, want %v", tt.at, got, tt.want)
+		}
+	}
+}
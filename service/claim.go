@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxActiveClaims limits the number of audiences one user may hold at the same time
+	MaxActiveClaims = 2
+	// MaxClaimDuration limits how far ahead an audience may be claimed
+	MaxClaimDuration = 3 * time.Hour
+)
+
+// Reasons for ClaimAudience to reject a claim, handlers tell them apart with errors.Is.
+var (
+	ErrorClaimExpired = &ValidationError{
+		ObjectKind: "Claim",
+		Message:    "claim is already expired",
+	}
+	ErrorClaimTooLong = &ValidationError{
+		ObjectKind: "Claim",
+		Message:    fmt.Sprintf("claim cannot be longer than %v", MaxClaimDuration),
+	}
+	ErrorClaimLimit = &ValidationError{
+		ObjectKind: "Claim",
+		Message:    fmt.Sprintf("at most %d audiences may be claimed at once", MaxActiveClaims),
+	}
+)
+
+// Claim marks an audience as taken by a user although it is free by timetable.
+type Claim struct {
+	ID         string
+	UserID     string
+	AudienceID string
+	ClaimedAt  time.Time
+	ExpiresAt  time.Time
+}
+
+// ClaimAudience marks the audience as taken by the user till expiresAt,
+// claiming the same audience again only moves its expiry.
+func (s *Service) ClaimAudience(ctx context.Context, userID, audienceID string, expiresAt time.Time) error {
+	now := time.Now()
+	if !expiresAt.After(now) {
+		return ErrorClaimExpired
+	}
+	if expiresAt.Sub(now) > MaxClaimDuration {
+		return ErrorClaimTooLong
+	}
+
+	claim := Claim{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		AudienceID: audienceID,
+		ClaimedAt:  now,
+		ExpiresAt:  expiresAt,
+	}
+	saved, err := s.scheduleStorage.SaveClaim(ctx, &claim, MaxActiveClaims)
+	if err != nil {
+		return fmt.Errorf("cannot save claim: %w", err)
+	}
+	if !saved {
+		return ErrorClaimLimit
+	}
+	return nil
+}
+
+// ListActiveClaims returns claims of the user active at the moment, the oldest first.
+func (s *Service) ListActiveClaims(ctx context.Context, userID string, at time.Time) ([]Claim, error) {
+	return s.scheduleStorage.ListActiveClaims(ctx, userID, at)
+}
+
+func (s *Service) ReleaseClaim(ctx context.Context, userID, audienceID string) error {
+	return s.scheduleStorage.DeleteClaim(ctx, userID, audienceID)
+}
+
+// DeleteExpiredClaims forgets claims expired by the moment, they never count again.
+func (s *Service) DeleteExpiredClaims(ctx context.Context, at time.Time) (int64, error) {
+	return s.scheduleStorage.DeleteExpiredClaims(ctx, at)
+}
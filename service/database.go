@@ -1,24 +1,69 @@
 package service
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type ScheduleStorage interface {
 	SaveUser(ctx context.Context, user *User) error
 	ListUsers(ctx context.Context, filters *UserFilters) ([]User, error)
+	// CountUsers counts users registered since createdSince, all users if it is nil
+	CountUsers(ctx context.Context, createdSince *time.Time) (int, error)
+
+	GetPreferences(ctx context.Context, userID string) (Preferences, error)
+	SavePreferences(ctx context.Context, prefs *Preferences) error
 
 	SaveAudiences(ctx context.Context, audiences ...Audience) error
 	ListAudienceByNumber(ctx context.Context, number string, suffix *string) (Audience, error)
+	GetAudience(ctx context.Context, id string) (Audience, error)
+	SaveAudienceAttributes(ctx context.Context, attrs *AudienceAttributes) error
+	ListFloors(ctx context.Context, buildings []string) ([]Floor, error)
 
 	SaveLessons(ctx context.Context, lessons ...Lesson) error
 	ListLessons(ctx context.Context, filters *LessonFilters) ([]Lesson, error)
+	ListTeachers(ctx context.Context, nameParts []string, limit uint64) ([]string, error)
 
 	SaveGroups(ctx context.Context, groups ...Group) error
 	ListGroups(ctx context.Context, filters *GroupFilters) ([]Group, error)
+	GetGroup(ctx context.Context, id string) (Group, error)
 
 	SaveGroupLessons(ctx context.Context, gls ...GroupLesson) error
 
 	SaveSchedules(ctx context.Context, lessons ...Schedule) error
 	ListSchedules(ctx context.Context, filters *ScheduleFilters) ([]Schedule, error)
+	ListScheduleEntries(ctx context.Context, filters *ScheduleEntryFilters) ([]ScheduleEntry, error)
+
+	ListEmptyAudiences(ctx context.Context, filters *EmptyAudiencesFilter) ([]EmptyAudience, error)
+
+	SaveFavorite(ctx context.Context, fav *FavoriteAudience) error
+	DeleteFavorite(ctx context.Context, userID, audienceID string) error
+	ListFavorites(ctx context.Context, userID string) ([]Audience, error)
+
+	SaveFreeAlert(ctx context.Context, alert *FreeAlert) error
+	ListFreeAlerts(ctx context.Context) ([]FreeAlert, error)
+	DeleteFreeAlert(ctx context.Context, id string) error
+
+	// SaveClaim saves the claim unless the user already holds maxActive other claims active at its start,
+	// the count and the insert are atomic
+	SaveClaim(ctx context.Context, claim *Claim, maxActive int) (bool, error)
+	DeleteClaim(ctx context.Context, userID, audienceID string) error
+	ListActiveClaims(ctx context.Context, userID string, at time.Time) ([]Claim, error)
+	// DeleteExpiredClaims deletes claims expired by the moment and returns how many there were
+	DeleteExpiredClaims(ctx context.Context, at time.Time) (int64, error)
+
+	// SaveReport saves the report and rates earlier reporters in one transaction,
+	// ratings maps their user IDs to whether the report agrees with theirs
+	SaveReport(ctx context.Context, report *RoomReport, ratings map[string]bool) error
+	ListReports(ctx context.Context, filters *RoomReportFilters) ([]RoomReport, error)
+	ListReputations(ctx context.Context, userIDs []string) ([]Reputation, error)
+
+	AddQueryCount(ctx context.Context, day time.Time, command string) error
+	ListQueryCounts(ctx context.Context, since time.Time) ([]QueryCount, error)
+
+	SaveImport(ctx context.Context, imp *ScheduleImport) error
+	GetLastImport(ctx context.Context) (ScheduleImport, error)
 
-	ListEmptyAudiences(ctx context.Context, filters *EmptyAudiencesFilter) ([]Audience, error)
+	GetUpdateOffset(ctx context.Context, botID int64) (UpdateOffset, error)
+	SaveUpdateOffset(ctx context.Context, offset *UpdateOffset) error
 }
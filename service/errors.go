@@ -7,6 +7,8 @@ import (
 
 var (
 	ErrorNotFound = errors.New("not found")
+	// ErrorTransient marks storage failures which may succeed if retried
+	ErrorTransient = errors.New("transient error")
 )
 
 type ValidationError struct {
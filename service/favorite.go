@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+type FavoriteAudience struct {
+	ID         string
+	UserID     string
+	AudienceID string
+}
+
+func (s *Service) AddFavorite(ctx context.Context, userID, audienceID string) error {
+	fav := FavoriteAudience{
+		ID:         uuid.NewString(),
+		UserID:     userID,
+		AudienceID: audienceID,
+	}
+	if err := s.scheduleStorage.SaveFavorite(ctx, &fav); err != nil {
+		return fmt.Errorf("cannot save favorite: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) RemoveFavorite(ctx context.Context, userID, audienceID string) error {
+	return s.scheduleStorage.DeleteFavorite(ctx, userID, audienceID)
+}
+
+func (s *Service) ListFavorites(ctx context.Context, userID string) ([]Audience, error) {
+	return s.scheduleStorage.ListFavorites(ctx, userID)
+}
+
+// AudienceStatus describes whether the audience is occupied at some period.
+type AudienceStatus struct {
+	Audience Audience
+	// Lessons are held in the audience at the period, empty if it is free
+	Lessons []ScheduleEntry
+	// NextBusyPeriod is the first occupied period after a free one, 0 if it stays free till the end of the day
+	NextBusyPeriod int
+	// NextFreePeriod is the first free period after an occupied one, 0 if it stays busy till the end of the day
+	NextFreePeriod int
+}
+
+func (as *AudienceStatus) Free() bool {
+	return len(as.Lessons) == 0
+}
+
+// AudienceStatuses returns status of every audience at the given period.
+func (s *Service) AudienceStatuses(ctx context.Context, audiences []Audience, weekType, weekDay string, period int) ([]AudienceStatus, error) {
+	if len(audiences) == 0 {
+		return []AudienceStatus{}, nil
+	}
+
+	ids := make([]string, 0, len(audiences))
+	for _, a := range audiences {
+		ids = append(ids, a.ID)
+	}
+	entries, err := s.ListScheduleEntries(ctx, &ScheduleEntryFilters{
+		AudienceIDs: ids,
+		WeekType:    &weekType,
+		WeekDay:     &weekDay,
+	})
+	if err != nil {
+		return []AudienceStatus{}, err
+	}
+
+	busy := make(map[string]map[int][]ScheduleEntry)
+	for _, e := range entries {
+		if _, ok := busy[e.Audience.ID]; !ok {
+			busy[e.Audience.ID] = make(map[int][]ScheduleEntry)
+		}
+		busy[e.Audience.ID][e.Period] = append(busy[e.Audience.ID][e.Period], e)
+	}
+
+	res := make([]AudienceStatus, 0, len(audiences))
+	for _, a := range audiences {
+		status := AudienceStatus{
+			Audience: a,
+			Lessons:  busy[a.ID][period],
+		}
+		for _, b := range Bells {
+			if b.Period <= period {
+				continue
+			}
+			_, isBusy := busy[a.ID][b.Period]
+			if status.Free() && isBusy && status.NextBusyPeriod == 0 {
+				status.NextBusyPeriod = b.Period
+			}
+			if !status.Free() && !isBusy && status.NextFreePeriod == 0 {
+				status.NextFreePeriod = b.Period
+			}
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
+
+// FreeAlert is a one-shot subscription to the moment the audience becomes free.
+type FreeAlert struct {
+	ID         string
+	UserID     string
+	TelegramID string
+	Audience   Audience
+}
+
+func (s *Service) SubscribeFreeAlert(ctx context.Context, userID, audienceID string) error {
+	alert := FreeAlert{
+		ID:       uuid.NewString(),
+		UserID:   userID,
+		Audience: Audience{ID: audienceID},
+	}
+	if err := s.scheduleStorage.SaveFreeAlert(ctx, &alert); err != nil {
+		return fmt.Errorf("cannot save free alert: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) ListFreeAlerts(ctx context.Context) ([]FreeAlert, error) {
+	return s.scheduleStorage.ListFreeAlerts(ctx)
+}
+
+func (s *Service) DeleteFreeAlert(ctx context.Context, id string) error {
+	return s.scheduleStorage.DeleteFreeAlert(ctx, id)
+}
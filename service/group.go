@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -59,3 +61,120 @@ func (s *Service) SaveGroupLessons(ctx context.Context, gls ...GroupLesson) ([]s
 
 	return glIDs, nil
 }
+
+var groupNameReplacer = strings.NewReplacer(
+	" ", "", "-", "", "_", "", ".", "",
+	// latin letters which look like cyrillic ones
+	"A", "А", "B", "В", "C", "С", "E", "Е", "H", "Н", "K", "К",
+	"M", "М", "O", "О", "P", "Р", "T", "Т", "X", "Х", "Y", "У",
+)
+
+func normalizeGroupName(name string) string {
+	return groupNameReplacer.Replace(strings.ToUpper(strings.TrimSpace(name)))
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	res := values[0]
+	for _, v := range values[1:] {
+		if v < res {
+			res = v
+		}
+	}
+	return res
+}
+
+const (
+	maxGroupMatches  = 10
+	maxGroupDistance = 2
+)
+
+// SearchGroups finds groups by a sloppy name: case, dashes, spaces and latin lookalikes are ignored,
+// and small typos are tolerated. An exact match is returned alone.
+func (s *Service) SearchGroups(ctx context.Context, name string) ([]Group, error) {
+	needle := normalizeGroupName(name)
+	if needle == "" {
+		return []Group{}, &ValidationError{
+			ObjectKind: "Group",
+			Message:    "empty group name",
+		}
+	}
+
+	groups, err := s.scheduleStorage.ListGroups(ctx, &GroupFilters{})
+	if err != nil {
+		return []Group{}, err
+	}
+
+	type match struct {
+		group Group
+		score int
+	}
+	matches := make([]match, 0)
+	for _, g := range groups {
+		normalized := normalizeGroupName(g.Name)
+		switch {
+		case normalized == needle:
+			return []Group{g}, nil
+		case strings.HasPrefix(normalized, needle):
+			matches = append(matches, match{group: g, score: 0})
+		case strings.Contains(normalized, needle):
+			matches = append(matches, match{group: g, score: 1})
+		default:
+			if d := levenshtein([]rune(normalized), []rune(needle)); d <= maxGroupDistance {
+				matches = append(matches, match{group: g, score: 1 + d})
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return []Group{}, ErrorNotFound
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score < matches[j].score
+		}
+		return matches[i].group.Name < matches[j].group.Name
+	})
+	if len(matches) > maxGroupMatches {
+		matches = matches[:maxGroupMatches]
+	}
+
+	res := make([]Group, 0, len(matches))
+	for _, m := range matches {
+		res = append(res, m.group)
+	}
+	return res, nil
+}
+
+// ListGroupSchedule returns lessons of the group; nil weekType or weekDay means any.
+func (s *Service) ListGroupSchedule(ctx context.Context, groupID string, weekType, weekDay *string) ([]ScheduleEntry, error) {
+	return s.ListScheduleEntries(ctx, &ScheduleEntryFilters{
+		GroupID:  &groupID,
+		WeekType: weekType,
+		WeekDay:  weekDay,
+	})
+}
+
+func (s *Service) GetGroup(ctx context.Context, id string) (Group, error) {
+	return s.scheduleStorage.GetGroup(ctx, id)
+}
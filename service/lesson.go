@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +20,21 @@ type LessonFilters struct {
 	Name *string
 }
 
+var (
+	militaryReg = regexp.MustCompile(`(?i)вуц`)
+	peReg       = regexp.MustCompile(`(?i)Элективный курс по физической культуре и спорту`)
+)
+
+// IsMilitaryTraining tells lessons of the military training centre (ВУЦ) by their name.
+func IsMilitaryTraining(name string) bool {
+	return militaryReg.MatchString(name)
+}
+
+// IsPhysicalEducation tells elective physical education lessons by their name.
+func IsPhysicalEducation(name string) bool {
+	return peReg.MatchString(name)
+}
+
 func (s *Service) SaveLessons(ctx context.Context, lessons ...Lesson) ([]string, error) {
 	lessonsToSave := make([]Lesson, 0, len(lessons))
 	lessonsIDs := make([]string, 0, len(lessons))
@@ -38,3 +55,40 @@ func (s *Service) SaveLessons(ctx context.Context, lessons ...Lesson) ([]string,
 func (s *Service) ListLessons(ctx context.Context, filters *LessonFilters) ([]Lesson, error) {
 	return s.scheduleStorage.ListLessons(ctx, filters)
 }
+
+const maxTeacherMatches = 10
+
+// SearchTeachers finds teacher names containing every word of the query, case-insensitively.
+func (s *Service) SearchTeachers(ctx context.Context, name string) ([]string, error) {
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return []string{}, &ValidationError{
+			ObjectKind: "Teacher",
+			Message:    "empty teacher name",
+		}
+	}
+
+	teachers, err := s.scheduleStorage.ListTeachers(ctx, words, maxTeacherMatches)
+	if err != nil {
+		return []string{}, err
+	}
+	if len(teachers) == 0 {
+		return []string{}, ErrorNotFound
+	}
+
+	for _, t := range teachers {
+		if strings.EqualFold(t, strings.Join(words, " ")) {
+			return []string{t}, nil
+		}
+	}
+
+	return teachers, nil
+}
+
+func (s *Service) ListTeacherSchedule(ctx context.Context, teacherName string, weekType, weekDay *string) ([]ScheduleEntry, error) {
+	return s.ListScheduleEntries(ctx, &ScheduleEntryFilters{
+		TeacherName: &teacherName,
+		WeekType:    weekType,
+		WeekDay:     weekDay,
+	})
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// UpdateOffset is the last telegram update the bot has handled, with every earlier one.
+type UpdateOffset struct {
+	BotID    int64
+	UpdateID int
+	// SavedAt is when the bot was last known to be running
+	SavedAt time.Time
+}
+
+// GetUpdateOffset returns ErrorNotFound if the bot has never saved its offset.
+func (s *Service) GetUpdateOffset(ctx context.Context, botID int64) (UpdateOffset, error) {
+	return s.scheduleStorage.GetUpdateOffset(ctx, botID)
+}
+
+// SaveUpdateOffset stores the offset, it never moves back.
+func (s *Service) SaveUpdateOffset(ctx context.Context, offset *UpdateOffset) error {
+	if offset.SavedAt.IsZero() {
+		offset.SavedAt = time.Now()
+	}
+	return s.scheduleStorage.SaveUpdateOffset(ctx, offset)
+}
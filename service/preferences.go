@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	LanguageRussian = "ru"
+	LanguageEnglish = "en"
+)
+
+type Preferences struct {
+	UserID          string
+	DefaultBuilding *string
+	DefaultFloor    *int
+	GroupID         *string
+	Language        *string
+	Notifications   bool
+}
+
+func defaultPreferences(userID string) Preferences {
+	return Preferences{
+		UserID:        userID,
+		Notifications: true,
+	}
+}
+
+func (p *Preferences) validate() error {
+	if p.UserID == "" {
+		return &ValidationError{
+			ObjectKind: "Preferences",
+			Message:    "empty user ID",
+		}
+	}
+	if p.DefaultBuilding != nil {
+		known := false
+		for _, b := range suffixMapping {
+			known = known || b == *p.DefaultBuilding
+		}
+		if !known {
+			return &ValidationError{
+				ObjectKind: "Preferences",
+				Message:    fmt.Sprintf("unknown building %s", *p.DefaultBuilding),
+			}
+		}
+	}
+	if p.DefaultFloor != nil && *p.DefaultFloor <= 0 {
+		return &ValidationError{
+			ObjectKind: "Preferences",
+			Message:    fmt.Sprintf("invalid floor %d", *p.DefaultFloor),
+		}
+	}
+	if p.Language != nil && *p.Language != LanguageRussian && *p.Language != LanguageEnglish {
+		return &ValidationError{
+			ObjectKind: "Preferences",
+			Message:    fmt.Sprintf("unsupported language %s", *p.Language),
+		}
+	}
+	return nil
+}
+
+// GetPreferences returns stored preferences of the user or the defaults if nothing is stored yet.
+func (s *Service) GetPreferences(ctx context.Context, userID string) (Preferences, error) {
+	prefs, err := s.scheduleStorage.GetPreferences(ctx, userID)
+	if errors.Is(err, ErrorNotFound) {
+		return defaultPreferences(userID), nil
+	}
+	return prefs, err
+}
+
+func (s *Service) SavePreferences(ctx context.Context, prefs *Preferences) error {
+	if err := prefs.validate(); err != nil {
+		return err
+	}
+	if err := s.scheduleStorage.SavePreferences(ctx, prefs); err != nil {
+		return fmt.Errorf("cannot save preferences: %w", err)
+	}
+	return nil
+}
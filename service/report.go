@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportStateOccupied = "occupied"
+	ReportStateLocked   = "locked"
+	ReportStateFree     = "free"
+
+	// ReportHalfLife is the age at which a report counts half as much as a fresh one
+	ReportHalfLife = 7 * 24 * time.Hour
+	// ReportMaxAge is the age after which reports are ignored
+	ReportMaxAge = 28 * 24 * time.Hour
+	// ReportConfirmWindow is how close in time two reports on one slot must be to confirm or contradict each other
+	ReportConfirmWindow = 2 * time.Hour
+
+	// UnavailableConfidence is the confidence above which an audience is flagged as unavailable
+	UnavailableConfidence = 0.5
+
+	// timetablePriorWeight is the weight of the timetable itself which says the audience is free
+	timetablePriorWeight = 1
+)
+
+// RoomReport is a user observation of the real state of an audience at a timetable slot.
+type RoomReport struct {
+	ID         string
+	UserID     string
+	AudienceID string
+	WeekType   string
+	WeekDay    string
+	Period     int
+	State      string
+	ReportedAt time.Time
+}
+
+// Unavailable tells whether the report says the audience cannot be used.
+func (r *RoomReport) Unavailable() bool {
+	return r.State != ReportStateFree
+}
+
+func (r *RoomReport) validate() error {
+	if r.State != ReportStateOccupied && r.State != ReportStateLocked && r.State != ReportStateFree {
+		return &ValidationError{
+			ObjectKind: "RoomReport",
+			Message:    fmt.Sprintf("unknown state %s", r.State),
+		}
+	}
+	if _, ok := BellByPeriod(r.Period); !ok {
+		return &ValidationError{
+			ObjectKind: "RoomReport",
+			Message:    fmt.Sprintf("unknown period %d", r.Period),
+		}
+	}
+	if weekDayIndex(r.WeekDay) == len(weekDays) {
+		return &ValidationError{
+			ObjectKind: "RoomReport",
+			Message:    fmt.Sprintf("unknown week day %s", r.WeekDay),
+		}
+	}
+	return nil
+}
+
+type RoomReportFilters struct {
+	AudienceIDs []string
+	WeekType    *string
+	WeekDays    []string
+	Periods     []int
+	Since       *time.Time
+}
+
+// Reputation counts how often reports of the user were confirmed by other users.
+type Reputation struct {
+	UserID string
+	Agreed int
+	Total  int
+}
+
+// Accuracy estimates the share of correct reports, users without history get 0.5.
+func (r *Reputation) Accuracy() float64 {
+	return float64(r.Agreed+1) / float64(r.Total+2)
+}
+
+// ReportRoom stores the report and updates reputation of users who reported the same slot shortly before.
+func (s *Service) ReportRoom(ctx context.Context, report *RoomReport) error {
+	if err := report.validate(); err != nil {
+		return err
+	}
+	report.ID = uuid.NewString()
+	if report.ReportedAt.IsZero() {
+		report.ReportedAt = time.Now()
+	}
+
+	since := report.ReportedAt.Add(-ReportConfirmWindow)
+	previous, err := s.scheduleStorage.ListReports(ctx, &RoomReportFilters{
+		AudienceIDs: []string{report.AudienceID},
+		WeekType:    &report.WeekType,
+		WeekDays:    []string{report.WeekDay},
+		Periods:     []int{report.Period},
+		Since:       &since,
+	})
+	if err != nil {
+		return err
+	}
+
+	ratings := rateReporters(report, previous)
+	if err := s.scheduleStorage.SaveReport(ctx, report, ratings); err != nil {
+		return fmt.Errorf("cannot save report: %w", err)
+	}
+	return nil
+}
+
+// rateReporters tells for every user who reported the slot shortly before whether the report agrees
+// with theirs. An earlier report is rated once per reporter: reports the same user made after it
+// have already rated it, and several reports of one user count once.
+func rateReporters(report *RoomReport, previous []RoomReport) map[string]bool {
+	ratings := make(map[string]bool)
+	for i, p := range previous {
+		if p.UserID == report.UserID {
+			continue
+		}
+		if _, ok := ratings[p.UserID]; ok {
+			continue
+		}
+		ratedBefore := false
+		for _, later := range previous[i+1:] {
+			if later.UserID == report.UserID && later.ReportedAt.After(p.ReportedAt) {
+				ratedBefore = true
+				break
+			}
+		}
+		if !ratedBefore {
+			ratings[p.UserID] = p.Unavailable() == report.Unavailable()
+		}
+	}
+	return ratings
+}
+
+// reportConfidence weighs reports of one slot by reporter accuracy and age, the timetable itself
+// votes for "free". Only the latest report of every user counts, repeating it changes nothing.
+func reportConfidence(reports []RoomReport, reputations map[string]Reputation, now time.Time) float64 {
+	latest := make(map[string]RoomReport, len(reports))
+	for _, r := range reports {
+		if l, ok := latest[r.UserID]; !ok || r.ReportedAt.After(l.ReportedAt) {
+			latest[r.UserID] = r
+		}
+	}
+
+	unavailable, total := 0.0, float64(timetablePriorWeight)
+	for _, r := range latest {
+		rep := reputations[r.UserID]
+		age := now.Sub(r.ReportedAt)
+		if age < 0 {
+			age = 0
+		}
+		w := rep.Accuracy() * math.Pow(0.5, float64(age)/float64(ReportHalfLife))
+		if r.Unavailable() {
+			unavailable += w
+		}
+		total += w
+	}
+	return unavailable / total
+}
+
+// applyReports fills UnavailableConfidence of the audiences and moves suspicious ones
+// to the end of their floor, keeping the building and floor order.
+func (s *Service) applyReports(ctx context.Context, filters *EmptyAudiencesFilter, audiences []EmptyAudience) error {
+	if len(audiences) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	since := now.Add(-ReportMaxAge)
+	ids := make([]string, 0, len(audiences))
+	for _, a := range audiences {
+		ids = append(ids, a.ID)
+	}
+	reports, err := s.scheduleStorage.ListReports(ctx, &RoomReportFilters{
+		AudienceIDs: ids,
+		WeekType:    &filters.WeekType,
+		WeekDays:    filters.WeekDays,
+		Periods:     filters.Periods,
+		Since:       &since,
+	})
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		return nil
+	}
+
+	reporters := make([]string, 0)
+	bySlot := make(map[string]map[slotKey][]RoomReport)
+	for _, r := range reports {
+		reporters = append(reporters, r.UserID)
+		if _, ok := bySlot[r.AudienceID]; !ok {
+			bySlot[r.AudienceID] = make(map[slotKey][]RoomReport)
+		}
+		key := slotKey{WeekDay: r.WeekDay, Period: r.Period}
+		bySlot[r.AudienceID][key] = append(bySlot[r.AudienceID][key], r)
+	}
+
+	reputations, err := s.scheduleStorage.ListReputations(ctx, reporters)
+	if err != nil {
+		return err
+	}
+	repByUser := make(map[string]Reputation, len(reputations))
+	for _, r := range reputations {
+		repByUser[r.UserID] = r
+	}
+
+	for i := range audiences {
+		// the audience is as unreliable as its worst requested slot
+		for _, slotReports := range bySlot[audiences[i].ID] {
+			if c := reportConfidence(slotReports, repByUser, now); c > audiences[i].UnavailableConfidence {
+				audiences[i].UnavailableConfidence = c
+			}
+		}
+	}
+
+	for start := 0; start < len(audiences); {
+		end := start
+		for end < len(audiences) && audiences[end].Building == audiences[start].Building && audiences[end].Floor == audiences[start].Floor {
+			end++
+		}
+		floor := audiences[start:end]
+		sort.SliceStable(floor, func(i, j int) bool {
+			return !floor[i].Suspicious() && floor[j].Suspicious()
+		})
+		start = end
+	}
+	return nil
+}
+
+type slotKey struct {
+	WeekDay string
+	Period  int
+}
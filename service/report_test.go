@@ -0,0 +1,118 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRateReporters(t *testing.T) {
+	at := func(min int) time.Time {
+		return time.Date(2026, time.September, 7, 9, min, 0, 0, MoscowLocation)
+	}
+	report := func(user, state string, min int) RoomReport {
+		return RoomReport{UserID: user, State: state, ReportedAt: at(min)}
+	}
+
+	tests := []struct {
+		name     string
+		previous []RoomReport
+		want     map[string]bool
+	}{
+		{
+			name: "no earlier reports",
+			want: map[string]bool{},
+		},
+		{
+			name:     "agreeing and contradicting reporters",
+			previous: []RoomReport{report("b", ReportStateLocked, 1), report("c", ReportStateFree, 2)},
+			want:     map[string]bool{"b": true, "c": false},
+		},
+		{
+			name:     "own reports are not rated",
+			previous: []RoomReport{report("a", ReportStateFree, 1)},
+			want:     map[string]bool{},
+		},
+		{
+			name:     "several reports of one user count once",
+			previous: []RoomReport{report("b", ReportStateOccupied, 1), report("b", ReportStateFree, 2)},
+			want:     map[string]bool{"b": true},
+		},
+		{
+			name:     "reports rated by an earlier report of the user",
+			previous: []RoomReport{report("b", ReportStateOccupied, 1), report("a", ReportStateOccupied, 2)},
+			want:     map[string]bool{},
+		},
+		{
+			name: "reports made after the earlier report of the user",
+			previous: []RoomReport{
+				report("b", ReportStateOccupied, 1),
+				report("a", ReportStateOccupied, 2),
+				report("c", ReportStateFree, 3),
+			},
+			want: map[string]bool{"c": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := report("a", ReportStateOccupied, 10)
+			if got := rateReporters(&r, tt.previous); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rateReporters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportConfidence(t *testing.T) {
+	now := time.Date(2026, time.September, 7, 9, 30, 0, 0, MoscowLocation)
+	report := func(user, state string, minAgo int) RoomReport {
+		return RoomReport{UserID: user, State: state, ReportedAt: now.Add(-time.Duration(minAgo) * time.Minute)}
+	}
+	trusted := map[string]Reputation{"b": {UserID: "b", Agreed: 8, Total: 8}}
+
+	tests := []struct {
+		name    string
+		reports []RoomReport
+		want    float64
+		flagged bool
+	}{
+		{
+			name:    "one report of a new user",
+			reports: []RoomReport{report("a", ReportStateOccupied, 0)},
+			want:    0.5 / 1.5,
+		},
+		{
+			name:    "a new user reports the slot twice",
+			reports: []RoomReport{report("a", ReportStateOccupied, 5), report("a", ReportStateOccupied, 0)},
+			want:    0.5 / 1.5,
+		},
+		{
+			name:    "the latest report of a user counts",
+			reports: []RoomReport{report("a", ReportStateOccupied, 5), report("a", ReportStateFree, 0)},
+			want:    0,
+		},
+		{
+			name:    "two new users",
+			reports: []RoomReport{report("a", ReportStateOccupied, 0), report("c", ReportStateLocked, 0)},
+			want:    1.0 / 2,
+			flagged: true,
+		},
+		{
+			name:    "a trusted user",
+			reports: []RoomReport{report("b", ReportStateLocked, 0)},
+			want:    0.9 / 1.9,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reportConfidence(tt.reports, trusted, now)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("reportConfidence() = %v, want %v", got, tt.want)
+			}
+			if flagged := got >= UnavailableConfidence; flagged != tt.flagged {
+				t.Errorf("flagged = %v, want %v", flagged, tt.flagged)
+			}
+		})
+	}
+}
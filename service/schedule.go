@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -94,3 +95,44 @@ func (s *Service) SaveSchedules(ctx context.Context, schedules ...Schedule) ([]s
 func (s *Service) ListSchedules(ctx context.Context, filters *ScheduleFilters) ([]Schedule, error) {
 	return s.scheduleStorage.ListSchedules(ctx, filters)
 }
+
+// ScheduleEntry is a schedule slot joined with its lesson, audience and groups.
+type ScheduleEntry struct {
+	ScheduleID string
+	WeekType   string
+	WeekDay    string
+	Period     int
+	Start      *time.Time
+	End        *time.Time
+	Lesson     Lesson
+	Audience   Audience
+	Groups     []string
+}
+
+type ScheduleEntryFilters struct {
+	AudienceIDs []string
+	GroupID     *string
+	// TeacherName is matched exactly, use SearchTeachers to resolve partial names
+	TeacherName *string
+	WeekType    *string
+	WeekDay     *string
+}
+
+func (s *Service) ListScheduleEntries(ctx context.Context, filters *ScheduleEntryFilters) ([]ScheduleEntry, error) {
+	entries, err := s.scheduleStorage.ListScheduleEntries(ctx, filters)
+	if err != nil {
+		return []ScheduleEntry{}, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].WeekType != entries[j].WeekType {
+			return entries[i].WeekType == WeekTypeNumerator
+		}
+		if di, dj := weekDayIndex(entries[i].WeekDay), weekDayIndex(entries[j].WeekDay); di != dj {
+			return di < dj
+		}
+		return entries[i].Period < entries[j].Period
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatsDays is how many days /stats looks back.
+const StatsDays = 7
+
+// QueryCount is how many times a command was used during a day.
+type QueryCount struct {
+	Day     time.Time
+	Command string
+	Count   int
+}
+
+// ScheduleImport is the outcome of one download and import of group timetables.
+type ScheduleImport struct {
+	ID         string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Files is the number of ics files found, Imported of them were saved
+	Files    int
+	Imported int
+	// QuarantinedFiles could not be parsed or saved and were moved aside
+	QuarantinedFiles int
+	// QuarantinedEvents do not match any bell and were left out of the timetable
+	QuarantinedEvents int
+	// Error is set if the import stopped before all files were processed
+	Error *string
+}
+
+type Stats struct {
+	Users int
+	// NewUsers registered during the last StatsDays days
+	NewUsers int
+	// Queries per day and command for the last StatsDays days, the oldest day first
+	Queries []QueryCount
+	// LastImport is nil if the timetable was never imported
+	LastImport *ScheduleImport
+}
+
+// CountQuery adds one use of the command today.
+func (s *Service) CountQuery(ctx context.Context, command string) error {
+	day := time.Now().In(MoscowLocation)
+	return s.scheduleStorage.AddQueryCount(ctx, time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC), command)
+}
+
+func (s *Service) SaveImport(ctx context.Context, imp *ScheduleImport) error {
+	imp.ID = uuid.NewString()
+	return s.scheduleStorage.SaveImport(ctx, imp)
+}
+
+// LastImport returns ErrorNotFound if the timetable was never imported.
+func (s *Service) LastImport(ctx context.Context) (ScheduleImport, error) {
+	return s.scheduleStorage.GetLastImport(ctx)
+}
+
+func (s *Service) Stats(ctx context.Context, now time.Time) (Stats, error) {
+	res := Stats{}
+	day := now.In(MoscowLocation)
+	since := time.Date(day.Year(), day.Month(), day.Day()-StatsDays+1, 0, 0, 0, 0, time.UTC)
+
+	var err error
+	if res.Users, err = s.scheduleStorage.CountUsers(ctx, nil); err != nil {
+		return Stats{}, fmt.Errorf("cannot count users: %w", err)
+	}
+	newSince := now.AddDate(0, 0, -StatsDays)
+	if res.NewUsers, err = s.scheduleStorage.CountUsers(ctx, &newSince); err != nil {
+		return Stats{}, fmt.Errorf("cannot count new users: %w", err)
+	}
+	if res.Queries, err = s.scheduleStorage.ListQueryCounts(ctx, since); err != nil {
+		return Stats{}, fmt.Errorf("cannot list query counts: %w", err)
+	}
+
+	imp, err := s.scheduleStorage.GetLastImport(ctx)
+	switch {
+	case errors.Is(err, ErrorNotFound):
+	case err != nil:
+		return Stats{}, fmt.Errorf("cannot get last import: %w", err)
+	default:
+		res.LastImport = &imp
+	}
+	return res, nil
+}
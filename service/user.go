@@ -10,13 +10,19 @@ type User struct {
 	ID         string
 	TelegramID string
 	Username   *string
+	FirstName  *string
+	LastName   *string
 	Phone      *string
+	// IsAdmin is set in the database by hand, it is never changed by SaveUser
+	IsAdmin bool
 }
 
 type UserFilters struct {
 	TelegramIDs []string
 }
 
+// SaveUser creates the user or updates profile fields of an existing one with the same telegram ID.
+// The ID of the stored user is returned and set to user.ID.
 func (s *Service) SaveUser(ctx context.Context, user *User) (string, error) {
 	user.ID = uuid.NewString()
 	if user.TelegramID == "" {